@@ -0,0 +1,128 @@
+package xmrrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"sync/atomic"
+)
+
+type BatchCall struct {
+	Method string      `json:"method"`
+	Params interface{} `json:"params"`
+	Result interface{} `json:"-"`
+	Err    error       `json:"-"`
+	id     uint64
+}
+
+type Batch struct {
+	dc    *DaemonClient
+	calls []*BatchCall
+}
+
+func (dc *DaemonClient) NewBatch() *Batch {
+	return &Batch{dc: dc}
+}
+
+func (b *Batch) Add(method string, params interface{}, result interface{}) *BatchCall {
+	call := &BatchCall{Method: method, Params: params, Result: result}
+	b.calls = append(b.calls, call)
+
+	return call
+}
+
+func (b *Batch) Calls() []*BatchCall {
+	return b.calls
+}
+
+func (b *Batch) Send() error {
+	return b.SendContext(context.Background())
+}
+
+func (b *Batch) SendContext(ctx context.Context) error {
+	if len(b.calls) == 0 {
+		return nil
+	}
+
+	if atomic.LoadUint32(&b.dc.batchUnsupported) == 1 {
+		return b.sendSequential(ctx)
+	}
+
+	id := rand.Uint64()
+	calls := make(map[uint64]*BatchCall, len(b.calls))
+	for i, call := range b.calls {
+		call.id, call.Err = id+uint64(i), nil
+		calls[call.id] = call
+	}
+
+	// The batch is one call of the pipeline, so interceptors, retries, limits
+	// and the circuit breaker apply to it as a whole.
+	var raw json.RawMessage
+	err := b.dc.invoke(ctx, &Invocation{Method: "batch", Endpoint: "/json_rpc", Params: b.calls, Result: &raw})
+	if err != nil {
+		return err
+	}
+
+	// Only a daemon unable to parse the array answers with a single error,
+	// batching is turned off for good then.
+	if raw = bytes.TrimSpace(raw); len(raw) == 0 || raw[0] != '[' {
+		var res jsonRPCResponse
+		if err := json.Unmarshal(raw, &res); err != nil || (res.Error.Code != ErrParse.Code && res.Error.Code != ErrInvalidRequest.Code) {
+			return errors.New("Unexpected batch response")
+		}
+
+		atomic.StoreUint32(&b.dc.batchUnsupported, 1)
+		return b.sendSequential(ctx)
+	}
+
+	var res []*jsonRPCResponse
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return err
+	}
+
+	for _, r := range res {
+		call, ok := calls[r.ID]
+		if !ok {
+			continue
+		}
+		delete(calls, r.ID)
+
+		call.Err = b.dc.jsonResult(call.Method, r, call.Result)
+	}
+
+	for _, call := range calls {
+		call.Err = errors.New("Missing response for batch call")
+	}
+
+	return nil
+}
+
+// methods returns the daemon methods performed by inv, the calls of a batch
+// or the single method otherwise.
+func (inv *Invocation) methods() []string {
+	calls, ok := inv.Params.([]*BatchCall)
+	if !ok || !inv.IsJSONRPC() {
+		return []string{inv.Method}
+	}
+
+	methods := make([]string, len(calls))
+	for i, call := range calls {
+		methods[i] = call.Method
+	}
+
+	return methods
+}
+
+func (b *Batch) sendSequential(ctx context.Context) error {
+	for _, call := range b.calls {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		call.Err = b.dc.jsonRequest(ctx, call.Method, call.Params, call.Result)
+	}
+
+	return nil
+}
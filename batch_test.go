@@ -0,0 +1,248 @@
+package xmrrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type batchTestSuite struct {
+	suite.Suite
+	requests int
+	batch    bool
+	params   []interface{}
+}
+
+func TestBatchTestSuite(t *testing.T) {
+	suite.Run(t, new(batchTestSuite))
+}
+
+func (s *batchTestSuite) response(req *jsonRPCRequest) *jsonRPCResponse {
+	res := &jsonRPCResponse{ID: req.ID, Version: "2.0"}
+	switch req.Method {
+	case "on_get_block_hash":
+		params := req.Params.([]interface{})
+		res.Result, _ = json.Marshal(fmt.Sprintf("hash-%v", params[0]))
+	case "submit_block":
+		res.Error = *statusErrorResponse
+	case "sync_info":
+		res.Result, _ = json.Marshal(&StatusResponse{Status: "BUSY"})
+	default:
+		res.Result, _ = json.Marshal(statusOkResponse)
+	}
+
+	return res
+}
+
+func (s *batchTestSuite) server() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests++
+
+		var raw json.RawMessage
+		json.NewDecoder(r.Body).Decode(&raw)
+
+		var batch []*jsonRPCRequest
+		if err := json.Unmarshal(raw, &batch); err == nil {
+			if !s.batch {
+				json.NewEncoder(w).Encode(&jsonRPCResponse{Version: "2.0", Error: jsonRPCError{Code: -32600, Message: "Invalid Request"}})
+				return
+			}
+
+			var res []*jsonRPCResponse
+			for i := len(batch) - 1; i >= 0; i-- {
+				s.params = append(s.params, batch[i].Params)
+				if batch[i].Method != "missing" {
+					res = append(res, s.response(batch[i]))
+				}
+			}
+			json.NewEncoder(w).Encode(res)
+			return
+		}
+
+		req := &jsonRPCRequest{}
+		json.Unmarshal(raw, req)
+		json.NewEncoder(w).Encode(s.response(req))
+	}))
+}
+
+func (s *batchTestSuite) SetupTest() {
+	s.requests = 0
+	s.batch = true
+	s.params = nil
+}
+
+func (s *batchTestSuite) TestSend() {
+	ts := s.server()
+	defer ts.Close()
+
+	dc := NewDaemonClient(ts.URL, "username", "password")
+	b := dc.NewBatch()
+
+	hashes := make([]string, 3)
+	for i := range hashes {
+		b.Add("on_get_block_hash", []int{i}, &hashes[i])
+	}
+
+	var info InfoResponse
+	var sync SyncInfoResponse
+	var block string
+	var missing StatusResponse
+	infoCall := b.Add("get_info", nil, &info)
+	syncCall := b.Add("sync_info", nil, &sync)
+	submitCall := b.Add("submit_block", []string{"0707e6bdfedc0..."}, &block)
+	missingCall := b.Add("missing", nil, &missing)
+
+	if assert.NoError(s.T(), b.Send()) {
+		assert.Equal(s.T(), 1, s.requests)
+		assert.Len(s.T(), b.Calls(), 7)
+		assert.Equal(s.T(), []string{"hash-0", "hash-1", "hash-2"}, hashes)
+		assert.NoError(s.T(), infoCall.Err)
+		assert.Equal(s.T(), "OK", info.Status)
+		assert.True(s.T(), errors.Is(syncCall.Err, ErrStatusBusy))
+		assert.True(s.T(), errors.Is(submitCall.Err, ErrBlockNotAccepted))
+		assert.Error(s.T(), missingCall.Err)
+	}
+}
+
+func (s *batchTestSuite) TestSendFallback() {
+	s.batch = false
+
+	ts := s.server()
+	defer ts.Close()
+
+	dc := NewDaemonClient(ts.URL, "username", "password")
+	for n := 0; n < 2; n++ {
+		b := dc.NewBatch()
+
+		hashes := make([]string, 3)
+		for i := range hashes {
+			b.Add("on_get_block_hash", []int{i}, &hashes[i])
+		}
+		submitCall := b.Add("submit_block", []string{"0707e6bdfedc0..."}, new(string))
+
+		if assert.NoError(s.T(), b.Send()) {
+			assert.Equal(s.T(), []string{"hash-0", "hash-1", "hash-2"}, hashes)
+			assert.True(s.T(), errors.Is(submitCall.Err, ErrBlockNotAccepted))
+		}
+	}
+
+	assert.Equal(s.T(), 9, s.requests, "Batch must not be retried after rejection.")
+}
+
+func (s *batchTestSuite) TestSendInterceptors() {
+	ts := s.server()
+	defer ts.Close()
+
+	var invocations []*Invocation
+	dc := NewDaemonClient(ts.URL, "username", "password", WithInterceptors(func(ctx context.Context, inv *Invocation, next Invoker) error {
+		invocations = append(invocations, inv)
+		return next(ctx, inv)
+	}))
+
+	b := dc.NewBatch()
+	b.Add("get_info", nil, new(InfoResponse))
+	b.Add("on_get_block_hash", []int{1}, new(string))
+
+	if assert.NoError(s.T(), b.Send()) && assert.Len(s.T(), invocations, 1) {
+		assert.Equal(s.T(), "batch", invocations[0].Method)
+		assert.Equal(s.T(), b.Calls(), invocations[0].Params)
+		assert.Equal(s.T(), []string{"get_info", "on_get_block_hash"}, invocations[0].methods())
+	}
+}
+
+func (s *batchTestSuite) TestSendPayment() {
+	ts := s.server()
+	defer ts.Close()
+
+	b := NewDaemonClient(ts.URL, "username", "password", WithRPCPayment(nil)).NewBatch()
+	b.Add("get_info", nil, new(InfoResponse))
+	b.Add("get_block_header_by_height", map[string]uint{"height": 1}, new(BlockHeaderResponse))
+
+	if assert.NoError(s.T(), b.Send()) && assert.Len(s.T(), s.params, 2) {
+		for _, params := range s.params {
+			assert.Contains(s.T(), params, "client")
+		}
+	}
+}
+
+func (s *batchTestSuite) TestSendEmpty() {
+	assert.NoError(s.T(), NewDaemonClient("", "username", "password").NewBatch().Send())
+}
+
+func (s *batchTestSuite) TestResponseID() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &jsonRPCRequest{}
+		json.NewDecoder(r.Body).Decode(req)
+		res := s.response(req)
+		res.ID++
+		json.NewEncoder(w).Encode(res)
+	}))
+	defer ts.Close()
+
+	_, err := NewDaemonClient(ts.URL, "username", "password").GetInfo()
+	assert.Error(s.T(), err)
+}
+
+func (s *batchTestSuite) TestSendTransientError() {
+	status := http.StatusServiceUnavailable
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests++
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+
+		var batch []*jsonRPCRequest
+		json.NewDecoder(r.Body).Decode(&batch)
+		var res []*jsonRPCResponse
+		for _, req := range batch {
+			res = append(res, s.response(req))
+		}
+		json.NewEncoder(w).Encode(res)
+	}))
+	defer ts.Close()
+
+	dc := NewDaemonClient(ts.URL, "username", "password")
+	for _, code := range []int{http.StatusServiceUnavailable, http.StatusInternalServerError, http.StatusBadGateway} {
+		status = code
+
+		b := dc.NewBatch()
+		b.Add("get_info", nil, new(InfoResponse))
+		b.Add("get_info", nil, new(InfoResponse))
+
+		var httpErr *HTTPError
+		if assert.ErrorAs(s.T(), b.Send(), &httpErr) {
+			assert.Equal(s.T(), code, httpErr.StatusCode)
+		}
+	}
+
+	status = http.StatusOK
+	b := dc.NewBatch()
+	b.Add("get_info", nil, new(InfoResponse))
+	b.Add("get_info", nil, new(InfoResponse))
+	assert.NoError(s.T(), b.Send())
+	assert.Equal(s.T(), 4, s.requests, "Transient errors must not turn batching off.")
+}
+
+func (s *batchTestSuite) TestSendUnexpectedResponse() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests++
+		w.Write([]byte(`{"status":"OK"}`))
+	}))
+	defer ts.Close()
+
+	dc := NewDaemonClient(ts.URL, "username", "password")
+	b := dc.NewBatch()
+	b.Add("get_info", nil, new(InfoResponse))
+
+	assert.EqualError(s.T(), b.Send(), "Unexpected batch response")
+	assert.Equal(s.T(), 1, s.requests)
+	assert.Zero(s.T(), dc.batchUnsupported)
+}
@@ -0,0 +1,183 @@
+package xmrrpc
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+type RequestedInfo uint8
+
+const (
+	BlocksOnly RequestedInfo = iota
+	BlocksAndPool
+	PoolOnly
+)
+
+type Hash [32]byte
+
+func ParseHash(s string) (h Hash, err error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return h, err
+	}
+
+	if len(b) != len(h) {
+		return h, fmt.Errorf("Unexpected hash length: %d", len(b))
+	}
+
+	copy(h[:], b)
+	return h, nil
+}
+
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+func (h Hash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+// UnmarshalText accepts an empty string as a zero hash, as monerod leaves
+// optional hashes empty.
+func (h *Hash) UnmarshalText(text []byte) (err error) {
+	if len(text) == 0 {
+		*h = Hash{}
+		return nil
+	}
+
+	*h, err = ParseHash(string(text))
+	return err
+}
+
+// TxBlobEntry is a transaction of a block, pruned transactions carry the hash
+// of their prunable part.
+type TxBlobEntry struct {
+	Blob         []byte `epee:"blob"`
+	PrunableHash Hash   `epee:"prunable_hash"`
+}
+
+// UnmarshalEpee accepts both forms sent by monerod: a plain blob for unpruned
+// blocks and a {blob, prunable_hash} section for pruned ones.
+func (e *TxBlobEntry) UnmarshalEpee(v interface{}) error {
+	switch v := v.(type) {
+	case []byte:
+		*e = TxBlobEntry{Blob: v}
+	case map[string]interface{}:
+		*e = TxBlobEntry{}
+		e.Blob, _ = v["blob"].([]byte)
+		if h, ok := v["prunable_hash"].([]byte); ok {
+			if len(h) != len(e.PrunableHash) {
+				return fmt.Errorf("Unexpected hash length: %d", len(h))
+			}
+			copy(e.PrunableHash[:], h)
+		}
+	default:
+		return errors.New("Unexpected transaction entry")
+	}
+
+	return nil
+}
+
+type BlockCompleteEntry struct {
+	Pruned      bool          `epee:"pruned"`
+	Block       []byte        `epee:"block"`
+	BlockWeight uint          `epee:"block_weight"`
+	Txs         []TxBlobEntry `epee:"txs"`
+}
+
+type TxOutputIndices struct {
+	Indices []uint `epee:"indices"`
+}
+
+type BlockOutputIndices struct {
+	Indices []TxOutputIndices `epee:"indices"`
+}
+
+type PoolTxInfo struct {
+	TxHash          Hash   `epee:"tx_hash"`
+	TxBlob          []byte `epee:"tx_blob"`
+	DoubleSpendSeen bool   `epee:"double_spend_seen"`
+}
+
+type BlocksBinResponse struct {
+	Blocks                  []BlockCompleteEntry `epee:"blocks"`
+	StartHeight             uint                 `epee:"start_height"`
+	CurrentHeight           uint                 `epee:"current_height"`
+	OutputIndices           []BlockOutputIndices `epee:"output_indices"`
+	DaemonTime              uint                 `epee:"daemon_time"`
+	PoolInfoExtent          uint8                `epee:"pool_info_extent"`
+	AddedPoolTxs            []PoolTxInfo         `epee:"added_pool_txs"`
+	RemainingAddedPoolTxids []Hash               `epee:"remaining_added_pool_txids,blob"`
+	RemovedPoolTxids        []Hash               `epee:"removed_pool_txids,blob"`
+	Credits                 uint                 `epee:"credits"`
+	Status                  string               `epee:"status"`
+	TopHash                 string               `epee:"top_hash"`
+	Untrusted               bool                 `epee:"untrusted"`
+}
+
+func (dc *DaemonClient) GetBlocksBin(startHeight uint, blockIDs []Hash, prune bool, noMinerTx bool, requestedInfo RequestedInfo, poolInfoSince uint) (response BlocksBinResponse, err error) {
+	return dc.GetBlocksBinContext(context.Background(), startHeight, blockIDs, prune, noMinerTx, requestedInfo, poolInfoSince)
+}
+
+func (dc *DaemonClient) GetBlocksBinContext(ctx context.Context, startHeight uint, blockIDs []Hash, prune bool, noMinerTx bool, requestedInfo RequestedInfo, poolInfoSince uint) (response BlocksBinResponse, err error) {
+	type Params struct {
+		RequestedInfo RequestedInfo `json:"requested_info" epee:"requested_info,omitempty"`
+		BlockIDs      []Hash        `json:"block_ids" epee:"block_ids,blob"`
+		StartHeight   uint          `json:"start_height" epee:"start_height"`
+		Prune         bool          `json:"prune" epee:"prune"`
+		NoMinerTx     bool          `json:"no_miner_tx" epee:"no_miner_tx,omitempty"`
+		PoolInfoSince uint          `json:"pool_info_since" epee:"pool_info_since,omitempty"`
+	}
+
+	params := Params{RequestedInfo: requestedInfo, BlockIDs: blockIDs, StartHeight: startHeight, Prune: prune, NoMinerTx: noMinerTx, PoolInfoSince: poolInfoSince}
+	return response, dc.rpcRequest(ctx, "/get_blocks.bin", params, &response)
+}
+
+type HashesBinResponse struct {
+	BlockIDs      []Hash `epee:"m_block_ids,blob"`
+	StartHeight   uint   `epee:"start_height"`
+	CurrentHeight uint   `epee:"current_height"`
+	Credits       uint   `epee:"credits"`
+	Status        string `epee:"status"`
+	TopHash       string `epee:"top_hash"`
+	Untrusted     bool   `epee:"untrusted"`
+}
+
+type OIndexesBinResponse struct {
+	OIndexes  []uint `epee:"o_indexes"`
+	Credits   uint   `epee:"credits"`
+	Status    string `epee:"status"`
+	TopHash   string `epee:"top_hash"`
+	Untrusted bool   `epee:"untrusted"`
+}
+
+// GetHashesBin returns the hashes of the main chain from the first of blockIDs
+// found in it, blockIDs must end with the genesis hash.
+func (dc *DaemonClient) GetHashesBin(blockIDs []Hash, startHeight uint) (response HashesBinResponse, err error) {
+	return dc.GetHashesBinContext(context.Background(), blockIDs, startHeight)
+}
+
+func (dc *DaemonClient) GetHashesBinContext(ctx context.Context, blockIDs []Hash, startHeight uint) (response HashesBinResponse, err error) {
+	type Params struct {
+		BlockIDs    []Hash `json:"block_ids" epee:"block_ids,blob"`
+		StartHeight uint   `json:"start_height" epee:"start_height"`
+	}
+
+	params := Params{BlockIDs: blockIDs, StartHeight: startHeight}
+	return response, dc.rpcRequest(ctx, "/get_hashes.bin", params, &response)
+}
+
+func (dc *DaemonClient) GetOIndexesBin(txid Hash) (response OIndexesBinResponse, err error) {
+	return dc.GetOIndexesBinContext(context.Background(), txid)
+}
+
+func (dc *DaemonClient) GetOIndexesBinContext(ctx context.Context, txid Hash) (response OIndexesBinResponse, err error) {
+	type Params struct {
+		TxID Hash `json:"txid" epee:"txid"`
+	}
+
+	params := Params{TxID: txid}
+	return response, dc.rpcRequest(ctx, "/get_o_indexes.bin", params, &response)
+}
@@ -0,0 +1,185 @@
+package xmrrpc
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stdfox/xmrrpc/epee"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type binTestSuite struct {
+	suite.Suite
+	ts      *httptest.Server
+	path    string
+	body    []byte
+	request map[string]interface{}
+	reply   interface{}
+}
+
+func (s *binTestSuite) SetupTest() {
+	s.path, s.body, s.request = "", nil, nil
+	s.ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.path = r.URL.Path
+		s.body, _ = ioutil.ReadAll(r.Body)
+		if r.Header.Get("Content-Type") != "application/octet-stream" || epee.Unmarshal(s.body, &s.request) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if fixture, ok := s.reply.(string); ok {
+			data, _ := ioutil.ReadFile(filepath.Join("testdata", fixture))
+			w.Write(data)
+			return
+		}
+
+		data, err := epee.Marshal(s.reply)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write(data)
+	}))
+}
+
+func (s *binTestSuite) TearDownTest() {
+	s.ts.Close()
+}
+
+func TestBinTestSuite(t *testing.T) {
+	suite.Run(t, new(binTestSuite))
+}
+
+func (s *binTestSuite) TestParseHash() {
+	h, err := ParseHash("418015bb9ae982a1975da7d79277c2705727a56894ba0fb246adaabb1f4632e3")
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), byte(0x41), h[0])
+	assert.Equal(s.T(), "418015bb9ae982a1975da7d79277c2705727a56894ba0fb246adaabb1f4632e3", h.String())
+
+	_, err = ParseHash("4180")
+	assert.EqualError(s.T(), err, "Unexpected hash length: 2")
+
+	_, err = ParseHash("zz")
+	assert.Error(s.T(), err)
+}
+
+func (s *binTestSuite) TestGetBlocksBin() {
+	type entry struct {
+		Block       []byte   `epee:"block"`
+		BlockWeight uint     `epee:"block_weight"`
+		Txs         [][]byte `epee:"txs"`
+	}
+
+	s.reply = struct {
+		Blocks         []entry              `epee:"blocks"`
+		StartHeight    uint                 `epee:"start_height"`
+		CurrentHeight  uint                 `epee:"current_height"`
+		OutputIndices  []BlockOutputIndices `epee:"output_indices"`
+		DaemonTime     uint                 `epee:"daemon_time"`
+		AddedPoolTxs   []PoolTxInfo         `epee:"added_pool_txs"`
+		RemovedPoolTxs []Hash               `epee:"removed_pool_txids,blob"`
+		Status         string               `epee:"status"`
+	}{
+		Blocks:         []entry{{Block: []byte{1, 2}, BlockWeight: 300, Txs: [][]byte{{3}, {4, 5}}}},
+		StartHeight:    10,
+		CurrentHeight:  20,
+		OutputIndices:  []BlockOutputIndices{{Indices: []TxOutputIndices{{Indices: []uint{7}}, {Indices: []uint{8, 9}}, {}}}},
+		DaemonTime:     1700000000,
+		AddedPoolTxs:   []PoolTxInfo{{TxHash: Hash{1}, TxBlob: []byte{6}, DoubleSpendSeen: true}},
+		RemovedPoolTxs: []Hash{{2}, {3}},
+		Status:         "OK",
+	}
+
+	res, err := NewDaemonClient(s.ts.URL, "", "").GetBlocksBin(10, []Hash{{9}}, false, true, BlocksAndPool, 5)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "/get_blocks.bin", s.path)
+	assert.Equal(s.T(), uint64(10), s.request["start_height"])
+	assert.Equal(s.T(), append([]byte{9}, make([]byte, 31)...), s.request["block_ids"])
+	assert.Equal(s.T(), true, s.request["no_miner_tx"])
+	assert.Equal(s.T(), uint8(1), s.request["requested_info"])
+	assert.Equal(s.T(), uint64(5), s.request["pool_info_since"])
+
+	assert.Equal(s.T(), uint(10), res.StartHeight)
+	assert.Equal(s.T(), uint(20), res.CurrentHeight)
+	assert.Equal(s.T(), uint(1700000000), res.DaemonTime)
+	assert.Equal(s.T(), []BlockCompleteEntry{{Block: []byte{1, 2}, BlockWeight: 300, Txs: []TxBlobEntry{{Blob: []byte{3}}, {Blob: []byte{4, 5}}}}}, res.Blocks)
+	assert.Equal(s.T(), []uint{8, 9}, res.OutputIndices[0].Indices[1].Indices)
+	assert.Equal(s.T(), []PoolTxInfo{{TxHash: Hash{1}, TxBlob: []byte{6}, DoubleSpendSeen: true}}, res.AddedPoolTxs)
+	assert.Equal(s.T(), []Hash{{2}, {3}}, res.RemovedPoolTxids)
+}
+
+func (s *binTestSuite) TestGetBlocksBinPruned() {
+	s.reply = BlocksBinResponse{
+		Blocks:        []BlockCompleteEntry{{Pruned: true, Block: []byte{1}, BlockWeight: 100, Txs: []TxBlobEntry{{Blob: []byte{2}, PrunableHash: Hash{3}}}}},
+		CurrentHeight: 1,
+		Status:        "OK",
+	}
+
+	res, err := NewDaemonClient(s.ts.URL, "", "").GetBlocksBin(0, nil, true, false, BlocksOnly, 0)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), true, s.request["prune"])
+	assert.NotContains(s.T(), s.request, "requested_info")
+	assert.NotContains(s.T(), s.request, "no_miner_tx")
+	assert.Equal(s.T(), []byte{}, s.request["block_ids"])
+	assert.Equal(s.T(), s.reply.(BlocksBinResponse).Blocks, res.Blocks)
+}
+
+func (s *binTestSuite) TestGetBlocksBinStatus() {
+	s.reply = BlocksBinResponse{Status: "Failed"}
+
+	_, err := NewDaemonClient(s.ts.URL, "", "").GetBlocksBin(0, nil, false, false, BlocksOnly, 0)
+	assert.ErrorIs(s.T(), err, ErrStatusFailed)
+	assert.EqualError(s.T(), err, "Unexpected status of get_blocks.bin: Failed")
+}
+
+func (s *binTestSuite) TestTxBlobEntry() {
+	var e TxBlobEntry
+	assert.EqualError(s.T(), e.UnmarshalEpee(uint64(1)), "Unexpected transaction entry")
+	assert.EqualError(s.T(), e.UnmarshalEpee(map[string]interface{}{"prunable_hash": []byte{1}}), "Unexpected hash length: 1")
+}
+
+func (s *binTestSuite) fixture(name string) []byte {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		s.T().Fatal(err)
+	}
+
+	return data
+}
+
+// Monerod keeps the entries of an epee section in a std::map, so its responses
+// list keys alphabetically. The *_declared.bin fixtures carry the same entries
+// in the declaration order of the daemon structs to check that decoding does
+// not depend on key order.
+func (s *binTestSuite) TestGetHashesBin() {
+	genesis, _ := ParseHash("418015bb9ae982a1975da7d79277c2705727a56894ba0fb246adaabb1f4632e3")
+	block, _ := ParseHash("771fbcd656ec1464d3a02ead5e18644030007a0fc664c0a964d30922821a8148")
+
+	for _, fixture := range []string{"get_hashes_response.bin", "get_hashes_response_declared.bin"} {
+		s.reply = fixture
+
+		res, err := NewDaemonClient(s.ts.URL, "", "").GetHashesBin([]Hash{block, genesis}, 2)
+		assert.NoError(s.T(), err, fixture)
+		assert.Equal(s.T(), "/get_hashes.bin", s.path)
+		assert.Equal(s.T(), s.fixture("get_hashes_request.bin"), s.body)
+		assert.Equal(s.T(), HashesBinResponse{BlockIDs: []Hash{genesis, block}, CurrentHeight: 3, Status: "OK"}, res, fixture)
+	}
+}
+
+func (s *binTestSuite) TestGetOIndexesBin() {
+	txid, _ := ParseHash("c88ce9783b4f11190d7b9c17a69c1c52200f9faaee8e98dd07e6811175177139")
+
+	for _, fixture := range []string{"get_o_indexes_response.bin", "get_o_indexes_response_declared.bin"} {
+		s.reply = fixture
+
+		res, err := NewDaemonClient(s.ts.URL, "", "").GetOIndexesBin(txid)
+		assert.NoError(s.T(), err, fixture)
+		assert.Equal(s.T(), "/get_o_indexes.bin", s.path)
+		assert.Equal(s.T(), s.fixture("get_o_indexes_request.bin"), s.body)
+		assert.Equal(s.T(), OIndexesBinResponse{OIndexes: []uint{5, 6, 4294967296}, Status: "OK"}, res, fixture)
+	}
+}
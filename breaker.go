@@ -0,0 +1,160 @@
+package xmrrpc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("Circuit breaker is open")
+
+var DefaultCircuitBreakerConfig = CircuitBreakerConfig{
+	Window:      20,
+	MinRequests: 10,
+	FailureRate: 0.5,
+	CoolDown:    30 * time.Second,
+	IsFailure:   IsRetryable,
+}
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+
+	return "unknown"
+}
+
+type CircuitBreakerConfig struct {
+	Window      int
+	MinRequests int
+	FailureRate float64
+	CoolDown    time.Duration
+	IsFailure   func(err error) bool
+}
+
+type breaker struct {
+	mu       sync.Mutex
+	config   CircuitBreakerConfig
+	state    CircuitState
+	results  []bool
+	pos      int
+	count    int
+	failures int
+	openedAt time.Time
+}
+
+func WithCircuitBreaker(config CircuitBreakerConfig) Option {
+	return func(dc *DaemonClient) {
+		if config.Window < 1 {
+			config.Window = DefaultCircuitBreakerConfig.Window
+		}
+		if config.MinRequests < 1 || config.MinRequests > config.Window {
+			config.MinRequests = config.Window
+		}
+		if config.FailureRate <= 0 {
+			config.FailureRate = DefaultCircuitBreakerConfig.FailureRate
+		}
+		if config.CoolDown <= 0 {
+			config.CoolDown = DefaultCircuitBreakerConfig.CoolDown
+		}
+		if config.IsFailure == nil {
+			config.IsFailure = IsRetryable
+		}
+
+		dc.breaker = &breaker{config: config, results: make([]bool, config.Window)}
+	}
+}
+
+func (dc *DaemonClient) CircuitState() CircuitState {
+	if dc.breaker == nil {
+		return CircuitClosed
+	}
+
+	dc.breaker.mu.Lock()
+	defer dc.breaker.mu.Unlock()
+
+	return dc.breaker.state
+}
+
+func (dc *DaemonClient) probe(ctx context.Context) error {
+	return dc.roundTrip(ctx, &Invocation{Method: "get_height", Endpoint: "/get_height", Params: struct{}{}, Result: &HeightResponse{}})
+}
+
+func (b *breaker) allow(ctx context.Context, probe func(ctx context.Context) error) error {
+	b.mu.Lock()
+	switch {
+	case b.state == CircuitClosed:
+		b.mu.Unlock()
+		return nil
+	case b.state == CircuitHalfOpen || time.Since(b.openedAt) < b.config.CoolDown:
+		b.mu.Unlock()
+		return ErrCircuitOpen
+	}
+	b.state = CircuitHalfOpen
+	b.mu.Unlock()
+
+	err := probe(ctx)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err != nil {
+		b.state, b.openedAt = CircuitOpen, time.Now()
+		return ErrCircuitOpen
+	}
+
+	b.state = CircuitClosed
+	b.reset()
+
+	return nil
+}
+
+func (b *breaker) record(err error) {
+	if errors.Is(err, context.Canceled) || errors.Is(err, ErrCircuitOpen) {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != CircuitClosed {
+		return
+	}
+
+	failure := err != nil && b.config.IsFailure(err)
+	if b.count == len(b.results) {
+		if b.results[b.pos] {
+			b.failures--
+		}
+	} else {
+		b.count++
+	}
+	b.results[b.pos] = failure
+	b.pos = (b.pos + 1) % len(b.results)
+	if failure {
+		b.failures++
+	}
+
+	if b.count >= b.config.MinRequests && float64(b.failures)/float64(b.count) >= b.config.FailureRate {
+		b.state, b.openedAt = CircuitOpen, time.Now()
+		b.reset()
+	}
+}
+
+func (b *breaker) reset() {
+	b.pos, b.count, b.failures = 0, 0, 0
+}
@@ -0,0 +1,143 @@
+package xmrrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type breakerTestSuite struct {
+	suite.Suite
+	ts      *httptest.Server
+	failing int32
+	calls   int32
+	probes  int32
+}
+
+func TestBreakerTestSuite(t *testing.T) {
+	suite.Run(t, new(breakerTestSuite))
+}
+
+func (s *breakerTestSuite) SetupTest() {
+	s.failing, s.calls, s.probes = 0, 0, 0
+	s.ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.calls, 1)
+		if r.URL.Path == "/get_height" {
+			atomic.AddInt32(&s.probes, 1)
+		}
+
+		if atomic.LoadInt32(&s.failing) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if r.URL.Path == "/json_rpc" {
+			var req jsonRPCRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			w.Write([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"status":"OK","fee":1}}`, req.ID)))
+			return
+		}
+
+		w.Write([]byte(`{"status":"OK","height":100}`))
+	}))
+}
+
+func (s *breakerTestSuite) TearDownTest() {
+	s.ts.Close()
+}
+
+func (s *breakerTestSuite) client() *DaemonClient {
+	return NewDaemonClient(s.ts.URL, "username", "password", WithCircuitBreaker(CircuitBreakerConfig{
+		Window:      4,
+		MinRequests: 4,
+		FailureRate: 0.5,
+		CoolDown:    50 * time.Millisecond,
+	}))
+}
+
+func (s *breakerTestSuite) TestOpen() {
+	dc := s.client()
+	assert.Equal(s.T(), CircuitClosed, dc.CircuitState())
+
+	atomic.StoreInt32(&s.failing, 1)
+	for i := 0; i < 4; i++ {
+		_, err := dc.GetFeeEstimate(10)
+		assert.False(s.T(), errors.Is(err, ErrCircuitOpen))
+	}
+	assert.Equal(s.T(), CircuitOpen, dc.CircuitState())
+
+	_, err := dc.GetFeeEstimate(10)
+	assert.True(s.T(), errors.Is(err, ErrCircuitOpen))
+	assert.Equal(s.T(), int32(4), atomic.LoadInt32(&s.calls))
+}
+
+func (s *breakerTestSuite) TestFailureRate() {
+	dc := s.client()
+
+	for i := 0; i < 8; i++ {
+		atomic.StoreInt32(&s.failing, int32(i%4/3))
+		dc.GetFeeEstimate(10)
+	}
+	assert.Equal(s.T(), CircuitClosed, dc.CircuitState())
+}
+
+func (s *breakerTestSuite) TestRecovery() {
+	dc := s.client()
+
+	atomic.StoreInt32(&s.failing, 1)
+	for i := 0; i < 4; i++ {
+		dc.GetFeeEstimate(10)
+	}
+	time.Sleep(60 * time.Millisecond)
+
+	_, err := dc.GetFeeEstimate(10)
+	assert.True(s.T(), errors.Is(err, ErrCircuitOpen))
+	assert.Equal(s.T(), int32(1), atomic.LoadInt32(&s.probes))
+	assert.Equal(s.T(), CircuitOpen, dc.CircuitState())
+
+	atomic.StoreInt32(&s.failing, 0)
+	time.Sleep(60 * time.Millisecond)
+
+	_, err = dc.GetFeeEstimate(10)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), int32(2), atomic.LoadInt32(&s.probes))
+	assert.Equal(s.T(), CircuitClosed, dc.CircuitState())
+}
+
+func (s *breakerTestSuite) TestIgnoredErrors() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req jsonRPCRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Write([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"error":{"code":-2,"message":"Too big height"}}`, req.ID)))
+	}))
+	defer ts.Close()
+
+	dc := NewDaemonClient(ts.URL, "username", "password", WithCircuitBreaker(CircuitBreakerConfig{Window: 2, FailureRate: 0.5, CoolDown: time.Minute}))
+	for i := 0; i < 4; i++ {
+		dc.GetBlockHeaderByHeight(1 << 30)
+	}
+	assert.Equal(s.T(), CircuitClosed, dc.CircuitState())
+}
+
+func (s *breakerTestSuite) TestWithRetryPolicy() {
+	policy := DefaultRetryPolicy
+	policy.InitialBackoff = time.Millisecond
+	dc := NewDaemonClient(s.ts.URL, "username", "password", WithRetryPolicy(policy), WithCircuitBreaker(CircuitBreakerConfig{
+		Window:      2,
+		FailureRate: 1,
+		CoolDown:    time.Minute,
+	}))
+
+	atomic.StoreInt32(&s.failing, 1)
+	_, err := dc.GetFeeEstimate(10)
+	assert.True(s.T(), errors.Is(err, ErrCircuitOpen))
+	assert.Equal(s.T(), int32(2), atomic.LoadInt32(&s.calls))
+}
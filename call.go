@@ -0,0 +1,27 @@
+package xmrrpc
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+func (dc *DaemonClient) Call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	return dc.jsonRequest(ctx, method, params, result)
+}
+
+func (dc *DaemonClient) CallRaw(ctx context.Context, method string, params interface{}) (result json.RawMessage, err error) {
+	return result, dc.jsonRequest(ctx, method, params, &result)
+}
+
+func (dc *DaemonClient) CallEndpoint(ctx context.Context, path string, params interface{}, result interface{}) error {
+	if params == nil {
+		params = struct{}{}
+	}
+
+	return dc.rpcRequest(ctx, "/"+strings.TrimPrefix(path, "/"), params, result)
+}
+
+func (dc *DaemonClient) CallEndpointRaw(ctx context.Context, path string, params interface{}) (result json.RawMessage, err error) {
+	return result, dc.CallEndpoint(ctx, path, params, &result)
+}
@@ -0,0 +1,129 @@
+package xmrrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type callTestSuite struct {
+	suite.Suite
+	ts   *httptest.Server
+	body string
+}
+
+func TestCallTestSuite(t *testing.T) {
+	suite.Run(t, new(callTestSuite))
+}
+
+func (s *callTestSuite) SetupTest() {
+	s.ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		s.body = string(body)
+
+		switch r.URL.Path {
+		case "/json_rpc":
+			req := &jsonRPCRequest{}
+			json.Unmarshal(body, req)
+
+			res := &jsonRPCResponse{ID: req.ID, Version: "2.0"}
+			switch req.Method {
+			case "get_miner_data":
+				res.Result = json.RawMessage(`{"height":2731375,"major_version":16,"status":"OK"}`)
+			case "calc_pow":
+				res.Result = json.RawMessage(`"d33fd8a5bb1ea41bd3bdde0ba0fc2a4bd9a5a5d5ab8a0ea8a0fa2e2e8bd4b4e0"`)
+			case "flush_cache":
+				res.Result = json.RawMessage(`{"status":"BUSY"}`)
+			case "prune_blockchain":
+			default:
+				res.Error = jsonRPCError{Code: -32601, Message: "Method not found"}
+			}
+			json.NewEncoder(w).Encode(res)
+		case "/get_net_stats":
+			w.Write([]byte(`{"start_time":1,"total_packets_in":2,"status":"OK"}`))
+		default:
+			w.Write([]byte(`{"status":"Failed"}`))
+		}
+	}))
+}
+
+func (s *callTestSuite) TearDownTest() {
+	s.ts.Close()
+}
+
+func (s *callTestSuite) TestCall() {
+	var res struct {
+		Height       uint   `json:"height"`
+		MajorVersion uint   `json:"major_version"`
+		Status       string `json:"status"`
+	}
+
+	dc := NewDaemonClient(s.ts.URL, "username", "password")
+	if assert.NoError(s.T(), dc.Call(context.Background(), "get_miner_data", nil, &res)) {
+		assert.Equal(s.T(), uint(2731375), res.Height)
+		assert.Equal(s.T(), uint(16), res.MajorVersion)
+	}
+
+	var hash string
+	if assert.NoError(s.T(), dc.Call(context.Background(), "calc_pow", map[string]interface{}{"major_version": 16}, &hash)) {
+		assert.Len(s.T(), hash, 64)
+		assert.Contains(s.T(), s.body, `"params":{"major_version":16}`)
+	}
+
+	assert.True(s.T(), errors.Is(dc.Call(context.Background(), "unknown", nil, &res), ErrMethodNotFound))
+	assert.True(s.T(), errors.Is(dc.Call(context.Background(), "flush_cache", nil, &res), ErrStatusBusy))
+	assert.EqualError(s.T(), dc.Call(context.Background(), "prune_blockchain", nil, &res), "Unexpected null result")
+}
+
+func (s *callTestSuite) TestCallRaw() {
+	dc := NewDaemonClient(s.ts.URL, "username", "password")
+
+	res, err := dc.CallRaw(context.Background(), "get_miner_data", nil)
+	if assert.NoError(s.T(), err) {
+		assert.JSONEq(s.T(), `{"height":2731375,"major_version":16,"status":"OK"}`, string(res))
+	}
+
+	res, err = dc.CallRaw(context.Background(), "flush_cache", nil)
+	if assert.True(s.T(), errors.Is(err, ErrStatusBusy)) {
+		assert.JSONEq(s.T(), `{"status":"BUSY"}`, string(res))
+	}
+
+	_, err = NewDaemonClient(s.ts.URL, "username", "password", WithoutStatusCheck()).CallRaw(context.Background(), "flush_cache", nil)
+	assert.NoError(s.T(), err)
+}
+
+func (s *callTestSuite) TestCallEndpoint() {
+	var res struct {
+		StartTime      uint   `json:"start_time"`
+		TotalPacketsIn uint   `json:"total_packets_in"`
+		Status         string `json:"status"`
+	}
+
+	dc := NewDaemonClient(s.ts.URL, "username", "password")
+	if assert.NoError(s.T(), dc.CallEndpoint(context.Background(), "get_net_stats", nil, &res)) {
+		assert.Equal(s.T(), uint(2), res.TotalPacketsIn)
+		assert.Equal(s.T(), "{}", s.body)
+	}
+
+	assert.True(s.T(), errors.Is(dc.CallEndpoint(context.Background(), "/pop_blocks", map[string]uint{"nblocks": 6}, &res), ErrStatusFailed))
+	assert.Equal(s.T(), `{"nblocks":6}`, s.body)
+}
+
+func (s *callTestSuite) TestCallEndpointRaw() {
+	dc := NewDaemonClient(s.ts.URL, "username", "password")
+
+	res, err := dc.CallEndpointRaw(context.Background(), "/get_net_stats", nil)
+	if assert.NoError(s.T(), err) {
+		assert.JSONEq(s.T(), `{"start_time":1,"total_packets_in":2,"status":"OK"}`, string(res))
+	}
+
+	_, err = dc.CallEndpointRaw(context.Background(), "/pop_blocks", nil)
+	assert.True(s.T(), errors.Is(err, ErrStatusFailed))
+}
@@ -1,17 +1,62 @@
 package xmrrpc
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"log/slog"
 	"math/rand"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/stdfox/xmrrpc/epee"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 type DaemonClient struct {
 	endpoint string
-	username string
-	password string
+	auth     *digestAuth
+	client   *http.Client
+	header   http.Header
+	timeout  time.Duration
+	noStatus bool
+	retry    *RetryPolicy
+	limits   limits
+	breaker  *breaker
+	router   func(ctx context.Context, inv *Invocation) error
+
+	interceptors  []Interceptor
+	logger        *slog.Logger
+	logLevels     LogLevels
+	metrics       Metrics
+	tracer        trace.Tracer
+	tlsConfig     *tls.Config
+	proxy         *url.URL
+	proxyRequired bool
+	transportErr  error
+
+	maxResponseSize       int64
+	methodMaxResponseSize map[string]int64
+
+	payment *payment
+
+	batchUnsupported uint32
+}
+
+type Invocation struct {
+	Method   string
+	Endpoint string
+	Params   interface{}
+	Result   interface{}
+	Header   http.Header
 }
 
 type jsonRPCRequest struct {
@@ -28,6 +73,14 @@ type jsonRPCResponse struct {
 	Error   jsonRPCError    `json:"error"`
 }
 
+// jsonRPCReply is a JSON RPC response whose result is decoded into the reply
+// of the call.
+type jsonRPCReply struct {
+	ID     uint64       `json:"id"`
+	Result replyDecoder `json:"result"`
+	Error  jsonRPCError `json:"error"`
+}
+
 type jsonRPCError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
@@ -124,6 +177,7 @@ type InfoResponse struct {
 	BlockSizeLimit           uint   `json:"block_size_limit"`
 	BlockSizeMedian          uint   `json:"block_size_median"`
 	BootstrapDaemonAddress   string `json:"bootstrap_daemon_address"`
+	BusySyncing              bool   `json:"busy_syncing"`
 	CumulativeDifficulty     uint   `json:"cumulative_difficulty"`
 	Difficulty               uint   `json:"difficulty"`
 	FreeSpace                uint   `json:"free_space"`
@@ -138,6 +192,7 @@ type InfoResponse struct {
 	Stagenet                 bool   `json:"stagenet"`
 	StartTime                uint   `json:"start_time"`
 	Status                   string `json:"status"`
+	Synchronized             bool   `json:"synchronized"`
 	Target                   uint   `json:"target"`
 	TargetHeight             uint   `json:"target_height"`
 	Testnet                  bool   `json:"testnet"`
@@ -258,6 +313,127 @@ type OutputDistributionResponse struct {
 	Untrusted     bool           `json:"untrusted"`
 }
 
+type TxBacklogEntry struct {
+	Fee    uint   `json:"fee"`
+	ID     string `json:"id"`
+	Weight uint   `json:"weight"`
+}
+
+type MinerDataResponse struct {
+	AlreadyGeneratedCoins uint             `json:"already_generated_coins"`
+	Difficulty            string           `json:"difficulty"`
+	Height                uint             `json:"height"`
+	MajorVersion          uint             `json:"major_version"`
+	MedianWeight          uint             `json:"median_weight"`
+	PrevID                string           `json:"prev_id"`
+	SeedHash              string           `json:"seed_hash"`
+	Status                string           `json:"status"`
+	TxBacklog             []TxBacklogEntry `json:"tx_backlog"`
+	Untrusted             bool             `json:"untrusted"`
+}
+
+type AuxPow struct {
+	Hash string `json:"hash"`
+	ID   string `json:"id"`
+}
+
+type AddAuxPowResponse struct {
+	AuxPow            []AuxPow `json:"aux_pow"`
+	BlockHashingBlob  string   `json:"blockhashing_blob"`
+	BlockTemplateBlob string   `json:"blocktemplate_blob"`
+	MerkleRoot        string   `json:"merkle_root"`
+	MerkleTreeDepth   uint     `json:"merkle_tree_depth"`
+	Status            string   `json:"status"`
+	Untrusted         bool     `json:"untrusted"`
+}
+
+type PruneBlockchainResponse struct {
+	Pruned      bool   `json:"pruned"`
+	PruningSeed uint   `json:"pruning_seed"`
+	Status      string `json:"status"`
+	Untrusted   bool   `json:"untrusted"`
+}
+
+type TxidsLooseResponse struct {
+	Status    string   `json:"status"`
+	Txids     []string `json:"txids"`
+	Untrusted bool     `json:"untrusted"`
+}
+
+type GenerateBlocksResponse struct {
+	Blocks    []string `json:"blocks"`
+	Height    uint     `json:"height"`
+	Status    string   `json:"status"`
+	Untrusted bool     `json:"untrusted"`
+}
+
+type BannedResponse struct {
+	Banned    bool   `json:"banned"`
+	Seconds   uint   `json:"seconds"`
+	Status    string `json:"status"`
+	Untrusted bool   `json:"untrusted"`
+}
+
+type RPCAccessInfoResponse struct {
+	Cookie              uint   `json:"cookie"`
+	Credits             uint   `json:"credits"`
+	CreditsPerHashFound uint   `json:"credits_per_hash_found"`
+	Diff                uint   `json:"diff"`
+	HashingBlob         string `json:"hashing_blob"`
+	Height              uint   `json:"height"`
+	NextSeedHash        string `json:"next_seed_hash"`
+	SeedHash            string `json:"seed_hash"`
+	SeedHeight          uint   `json:"seed_height"`
+	Status              string `json:"status"`
+	TopHash             string `json:"top_hash"`
+	Untrusted           bool   `json:"untrusted"`
+}
+
+type RPCAccessResponse struct {
+	Credits   uint   `json:"credits"`
+	Status    string `json:"status"`
+	TopHash   string `json:"top_hash"`
+	Untrusted bool   `json:"untrusted"`
+}
+
+type RPCAccessTrackingEntry struct {
+	Count   uint   `json:"count"`
+	Credits uint   `json:"credits"`
+	RPC     string `json:"rpc"`
+	Time    uint   `json:"time"`
+}
+
+type RPCAccessTrackingResponse struct {
+	Data      []RPCAccessTrackingEntry `json:"data"`
+	Status    string                   `json:"status"`
+	Untrusted bool                     `json:"untrusted"`
+}
+
+type RPCAccessDataEntry struct {
+	Balance        uint   `json:"balance"`
+	Client         string `json:"client"`
+	CreditsTotal   uint   `json:"credits_total"`
+	CreditsUsed    uint   `json:"credits_used"`
+	LastUpdateTime uint   `json:"last_update_time"`
+	NoncesBad      uint   `json:"nonces_bad"`
+	NoncesDupe     uint   `json:"nonces_dupe"`
+	NoncesGood     uint   `json:"nonces_good"`
+	NoncesStale    uint   `json:"nonces_stale"`
+}
+
+type RPCAccessDataResponse struct {
+	Entries   []RPCAccessDataEntry `json:"entries"`
+	Hashrate  uint                 `json:"hashrate"`
+	Status    string               `json:"status"`
+	Untrusted bool                 `json:"untrusted"`
+}
+
+type RPCAccessAccountResponse struct {
+	Credits   uint   `json:"credits"`
+	Status    string `json:"status"`
+	Untrusted bool   `json:"untrusted"`
+}
+
 type HeightResponse struct {
 	Height    uint   `json:"height"`
 	Status    string `json:"status"`
@@ -410,153 +586,494 @@ type UpdateResponse struct {
 	Version string `json:"version"`
 }
 
-func NewDaemonClient(endpoint string, username string, password string) *DaemonClient {
-	return &DaemonClient{endpoint: endpoint, username: username, password: password}
+type PublicNode struct {
+	Host              string `json:"host"`
+	LastSeen          uint   `json:"last_seen"`
+	RPCCreditsPerHash uint   `json:"rpc_credits_per_hash"`
+	RPCPort           uint   `json:"rpc_port"`
+}
+
+type PublicNodesResponse struct {
+	Gray      []PublicNode `json:"gray"`
+	Status    string       `json:"status"`
+	Untrusted bool         `json:"untrusted"`
+	White     []PublicNode `json:"white"`
 }
 
-func (dc *DaemonClient) jsonRequest(method string, args interface{}, reply interface{}) error {
+type NetStatsResponse struct {
+	StartTime       uint   `json:"start_time"`
+	Status          string `json:"status"`
+	TotalBytesIn    uint   `json:"total_bytes_in"`
+	TotalBytesOut   uint   `json:"total_bytes_out"`
+	TotalPacketsIn  uint   `json:"total_packets_in"`
+	TotalPacketsOut uint   `json:"total_packets_out"`
+	Untrusted       bool   `json:"untrusted"`
+}
+
+type TransactionPoolHashesResponse struct {
+	Status    string   `json:"status"`
+	TxHashes  []string `json:"tx_hashes"`
+	Untrusted bool     `json:"untrusted"`
+}
+
+type PopBlocksResponse struct {
+	Height    uint   `json:"height"`
+	Status    string `json:"status"`
+	Untrusted bool   `json:"untrusted"`
+}
+
+func NewDaemonClient(endpoint string, username string, password string, opts ...Option) *DaemonClient {
+	dc := &DaemonClient{
+		endpoint:  endpoint,
+		auth:      newDigestAuth(username, password),
+		client:    http.DefaultClient,
+		header:    http.Header{},
+		logLevels: DefaultLogLevels,
+		tracer:    noop.NewTracerProvider().Tracer(tracerName),
+	}
+
+	for _, opt := range opts {
+		opt(dc)
+	}
+	dc.auth.onChallenge = dc.challenged
+
+	// Without a proxy .onion and .i2p hostnames would leak to the local resolver.
+	dc.proxyRequired = dc.proxy == nil && dc.client == http.DefaultClient && isAnonymousHost(endpoint)
+
+	if dc.timeout > 0 || dc.tlsConfig != nil || dc.proxy != nil {
+		client := *dc.client
+		if dc.timeout > 0 {
+			client.Timeout = dc.timeout
+		}
+		var ok bool
+		if client.Transport, ok = dc.transport(client.Transport); !ok {
+			// Calls fail rather than skip the proxy or certificate checks.
+			dc.transportErr = ErrUnsupportedTransport
+			dc.proxyRequired = dc.proxy != nil && isAnonymousHost(endpoint)
+		}
+		dc.client = &client
+	}
+
+	return dc
+}
+
+func (dc *DaemonClient) Endpoint() string {
+	return dc.endpoint
+}
+
+func (dc *DaemonClient) jsonRequest(ctx context.Context, method string, args interface{}, reply interface{}) error {
+	return dc.invoke(ctx, &Invocation{Method: method, Endpoint: "/json_rpc", Params: args, Result: reply})
+}
+
+func (dc *DaemonClient) rpcRequest(ctx context.Context, method string, args interface{}, reply interface{}) error {
+	return dc.invoke(ctx, &Invocation{Method: strings.TrimPrefix(method, "/"), Endpoint: method, Params: args, Result: reply})
+}
+
+func (dc *DaemonClient) dispatch(ctx context.Context, inv *Invocation) (err error) {
+	if dc.tracer != nil {
+		var end func(err error)
+		ctx, end = dc.startSpan(ctx, inv)
+		defer func() { end(err) }()
+	}
+
+	if dc.metrics != nil {
+		dc.metrics.InFlight(inv.Method, 1)
+		defer func(start time.Time) {
+			dc.metrics.InFlight(inv.Method, -1)
+			dc.metrics.Observe(inv.Method, time.Since(start), err)
+		}(time.Now())
+	}
+
+	if dc.router != nil {
+		return dc.router(ctx, inv)
+	}
+
+	if dc.retry == nil {
+		return dc.attempt(ctx, inv)
+	}
+
+	attempts := 0
+	return dc.retry.do(ctx, inv, func() error {
+		if attempts++; attempts > 1 && dc.metrics != nil {
+			dc.metrics.Retry(inv.Method)
+		}
+
+		return dc.attempt(ctx, inv)
+	})
+}
+
+func (dc *DaemonClient) attempt(ctx context.Context, inv *Invocation) error {
+	if dc.breaker != nil {
+		if err := dc.breaker.allow(ctx, dc.probe); err != nil {
+			return err
+		}
+	}
+
+	release, err := dc.limits.acquire(ctx, inv.methods()...)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	err = dc.roundTrip(ctx, inv)
+	if dc.breaker != nil {
+		dc.breaker.record(err)
+	}
+
+	return err
+}
+
+func (dc *DaemonClient) roundTrip(ctx context.Context, inv *Invocation) (err error) {
+	var ex exchange
+	if dc.logger != nil {
+		defer func(start time.Time) {
+			dc.logExchange(ctx, inv, ex, time.Since(start), err)
+		}(time.Now())
+	}
+	defer func() {
+		if ex.status != 0 {
+			trace.SpanFromContext(ctx).SetAttributes(attribute.Int("http.response.status_code", ex.status))
+		}
+	}()
+
+	if calls, ok := inv.Params.([]*BatchCall); ok && inv.IsJSONRPC() {
+		ex, err = dc.postBatch(ctx, inv, calls)
+		return err
+	}
+
+	args := inv.Params
+	if dc.payment != nil {
+		if args, err = dc.payment.sign(inv.Endpoint, args); err != nil {
+			return err
+		}
+	}
+
+	if strings.HasSuffix(inv.Endpoint, ".bin") {
+		if ex, err = dc.postBinary(ctx, inv.Method, inv.Endpoint, inv.Header, args, inv.Result); err != nil {
+			return err
+		}
+
+		return dc.checkStatus(inv.Method, inv.Result)
+	}
+
+	if inv.Endpoint != "/json_rpc" {
+		if ex, err = dc.post(ctx, inv.Method, inv.Endpoint, inv.Header, args, &replyDecoder{reply: inv.Result, payment: dc.payment}); err != nil {
+			return err
+		}
+
+		return dc.checkStatus(inv.Method, inv.Result)
+	}
+
 	params := &jsonRPCRequest{
 		Version: "2.0",
 		ID:      rand.Uint64(),
-		Method:  method,
+		Method:  inv.Method,
 		Params:  args,
 	}
 
-	res := &jsonRPCResponse{}
-	if err := dc.rpcRequest("/json_rpc", params, res); err != nil {
+	res := &jsonRPCReply{Result: replyDecoder{reply: inv.Result, payment: dc.payment}}
+	if ex, err = dc.post(ctx, inv.Method, "/json_rpc", inv.Header, params, res); err != nil {
 		return err
 	}
 
-	if res.Error.Code < 0 {
-		return errors.New(res.Error.Message)
+	if res.ID != params.ID {
+		return fmt.Errorf("Unexpected response id: %d, expected: %d", res.ID, params.ID)
+	}
+
+	if res.Error.Code != 0 {
+		return &RPCError{Code: res.Error.Code, Message: res.Error.Message, Method: inv.Method}
+	}
+
+	if !res.Result.decoded {
+		return errors.New("Unexpected null result")
+	}
+
+	return dc.checkStatus(inv.Method, inv.Result)
+}
+
+func (dc *DaemonClient) postBatch(ctx context.Context, inv *Invocation, calls []*BatchCall) (ex exchange, err error) {
+	params := make([]*jsonRPCRequest, len(calls))
+	for i, call := range calls {
+		args := call.Params
+		if dc.payment != nil {
+			if args, err = dc.payment.sign(inv.Endpoint, args); err != nil {
+				return ex, err
+			}
+		}
+
+		params[i] = &jsonRPCRequest{Version: "2.0", ID: call.id, Method: call.Method, Params: args}
+	}
+
+	return dc.post(ctx, inv.Method, inv.Endpoint, inv.Header, params, inv.Result)
+}
+
+func (dc *DaemonClient) jsonResult(method string, res *jsonRPCResponse, reply interface{}) error {
+	if res.Error.Code != 0 {
+		return &RPCError{Code: res.Error.Code, Message: res.Error.Message, Method: method}
 	}
 
 	if res.Result == nil {
 		return errors.New("Unexpected null result")
 	}
 
-	return json.Unmarshal(res.Result, reply)
+	if err := json.Unmarshal(res.Result, reply); err != nil {
+		return err
+	}
+
+	if dc.payment != nil {
+		dc.payment.trackJSON(res.Result)
+	}
+
+	return dc.checkStatus(method, reply)
 }
 
-func (dc *DaemonClient) rpcRequest(method string, args interface{}, reply interface{}) error {
+func (dc *DaemonClient) post(ctx context.Context, method string, path string, header http.Header, args interface{}, reply interface{}) (exchange, error) {
 	body, err := json.Marshal(args)
 	if err != nil {
-		return err
+		return exchange{}, err
 	}
 
-	res, err := request(http.MethodPost, dc.endpoint+method, body, dc.username, dc.password)
+	return dc.send(ctx, method, path, header, "application/json", body, func(r io.Reader) error {
+		return decodeResponse(r, reply)
+	})
+}
+
+func (dc *DaemonClient) postBinary(ctx context.Context, method string, path string, header http.Header, args interface{}, reply interface{}) (exchange, error) {
+	body, err := epee.Marshal(args)
 	if err != nil {
-		return err
+		return exchange{}, err
+	}
+
+	return dc.send(ctx, method, path, header, "application/octet-stream", body, func(r io.Reader) error {
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+
+		if err := epee.Unmarshal(data, reply); err != nil {
+			return err
+		}
+
+		if dc.payment != nil {
+			dc.payment.trackEpee(data)
+		}
+
+		return nil
+	})
+}
+
+func (dc *DaemonClient) send(ctx context.Context, method string, path string, extra http.Header, contentType string, body []byte, decode func(r io.Reader) error) (ex exchange, err error) {
+	if dc.proxyRequired {
+		return ex, ErrProxyRequired
+	}
+	if dc.transportErr != nil {
+		return ex, dc.transportErr
 	}
 
-	body, err = ioutil.ReadAll(res.Body)
+	header := dc.header.Clone()
+	for k, v := range extra {
+		header[http.CanonicalHeaderKey(k)] = v
+	}
+	header.Set("Content-Type", contentType)
+
+	ex.header = header
+
+	res, err := request(ctx, dc.client, http.MethodPost, dc.endpoint+path, header, body, dc.auth)
 	if err != nil {
-		return err
+		return ex, err
+	}
+	defer res.Body.Close()
+
+	ex.status = res.StatusCode
+
+	res.Body, err = responseBody(res)
+	if err != nil {
+		return ex, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return ex, newHTTPError(res)
+	}
+
+	r := &sizeReader{r: res.Body, method: method, limit: dc.responseLimit(method)}
+	err = decode(r)
+	ex.size = int(r.n)
+
+	return ex, err
+}
+
+func (dc *DaemonClient) checkStatus(method string, reply interface{}) error {
+	if dc.noStatus {
+		return nil
 	}
-	res.Body.Close()
 
-	return json.Unmarshal(body, reply)
+	return checkStatus(method, reply)
 }
 
 func (dc *DaemonClient) GetBlockCount() (response BlockCountResponse, err error) {
-	return response, dc.jsonRequest("get_block_count", nil, &response)
+	return dc.GetBlockCountContext(context.Background())
+}
+
+func (dc *DaemonClient) GetBlockCountContext(ctx context.Context) (response BlockCountResponse, err error) {
+	return response, dc.jsonRequest(ctx, "get_block_count", nil, &response)
 }
 
 func (dc *DaemonClient) OnGetBlockHash(blockHeight int) (response string, err error) {
-	return response, dc.jsonRequest("on_get_block_hash", []int{blockHeight}, &response)
+	return dc.OnGetBlockHashContext(context.Background(), blockHeight)
+}
+
+func (dc *DaemonClient) OnGetBlockHashContext(ctx context.Context, blockHeight int) (response string, err error) {
+	return response, dc.jsonRequest(ctx, "on_get_block_hash", []int{blockHeight}, &response)
 }
 
 func (dc *DaemonClient) GetBlockTemplate(walletAddress string, reserveSize uint) (response BlockTemplateResponse, err error) {
+	return dc.GetBlockTemplateContext(context.Background(), walletAddress, reserveSize)
+}
+
+func (dc *DaemonClient) GetBlockTemplateContext(ctx context.Context, walletAddress string, reserveSize uint) (response BlockTemplateResponse, err error) {
 	type Params struct {
 		WalletAddress string `json:"wallet_address"`
 		ReserveSize   uint   `json:"reserve_size"`
 	}
 
 	params := Params{WalletAddress: walletAddress, ReserveSize: reserveSize}
-	return response, dc.jsonRequest("get_block_template", params, &response)
+	return response, dc.jsonRequest(ctx, "get_block_template", params, &response)
 }
 
 func (dc *DaemonClient) SubmitBlock(blockBlobData string) (response string, err error) {
-	return response, dc.jsonRequest("submit_block", []string{blockBlobData}, &response)
+	return dc.SubmitBlockContext(context.Background(), blockBlobData)
+}
+
+func (dc *DaemonClient) SubmitBlockContext(ctx context.Context, blockBlobData string) (response string, err error) {
+	return response, dc.jsonRequest(ctx, "submit_block", []string{blockBlobData}, &response)
 }
 
 func (dc *DaemonClient) GetLastBlockHeader() (response BlockHeaderResponse, err error) {
-	return response, dc.jsonRequest("get_last_block_header", nil, &response)
+	return dc.GetLastBlockHeaderContext(context.Background())
+}
+
+func (dc *DaemonClient) GetLastBlockHeaderContext(ctx context.Context) (response BlockHeaderResponse, err error) {
+	return response, dc.jsonRequest(ctx, "get_last_block_header", nil, &response)
 }
 
 func (dc *DaemonClient) GetBlockHeaderByHash(hash string) (response BlockHeaderResponse, err error) {
+	return dc.GetBlockHeaderByHashContext(context.Background(), hash)
+}
+
+func (dc *DaemonClient) GetBlockHeaderByHashContext(ctx context.Context, hash string) (response BlockHeaderResponse, err error) {
 	type Params struct {
 		Hash string `json:"hash"`
 	}
 
 	params := Params{Hash: hash}
-	return response, dc.jsonRequest("get_block_header_by_hash", params, &response)
+	return response, dc.jsonRequest(ctx, "get_block_header_by_hash", params, &response)
 }
 
 func (dc *DaemonClient) GetBlockHeaderByHeight(height uint) (response BlockHeaderResponse, err error) {
+	return dc.GetBlockHeaderByHeightContext(context.Background(), height)
+}
+
+func (dc *DaemonClient) GetBlockHeaderByHeightContext(ctx context.Context, height uint) (response BlockHeaderResponse, err error) {
 	type Params struct {
 		Height uint `json:"height"`
 	}
 
 	params := Params{Height: height}
-	return response, dc.jsonRequest("get_block_header_by_height", params, &response)
+	return response, dc.jsonRequest(ctx, "get_block_header_by_height", params, &response)
 }
 
 func (dc *DaemonClient) GetBlockHeadersRange(startHeight uint, endHeight uint) (response BlockHeadersResponse, err error) {
+	return dc.GetBlockHeadersRangeContext(context.Background(), startHeight, endHeight)
+}
+
+func (dc *DaemonClient) GetBlockHeadersRangeContext(ctx context.Context, startHeight uint, endHeight uint) (response BlockHeadersResponse, err error) {
 	type Params struct {
 		StartHeight uint `json:"start_height"`
 		EndHeight   uint `json:"end_height"`
 	}
 
 	params := Params{StartHeight: startHeight, EndHeight: endHeight}
-	return response, dc.jsonRequest("get_block_headers_range", params, &response)
+	return response, dc.jsonRequest(ctx, "get_block_headers_range", params, &response)
 }
 
 func (dc *DaemonClient) GetBlock(height uint, hash string) (response BlockResponse, err error) {
+	return dc.GetBlockContext(context.Background(), height, hash)
+}
+
+func (dc *DaemonClient) GetBlockContext(ctx context.Context, height uint, hash string) (response BlockResponse, err error) {
 	type Params struct {
 		Height uint   `json:"height"`
 		Hash   string `json:"hash"`
 	}
 
 	params := Params{Height: height, Hash: hash}
-	return response, dc.jsonRequest("get_block", params, &response)
+	return response, dc.jsonRequest(ctx, "get_block", params, &response)
 }
 
 func (dc *DaemonClient) GetConnections() (response ConnectionsResponse, err error) {
-	return response, dc.jsonRequest("get_connections", nil, &response)
+	return dc.GetConnectionsContext(context.Background())
+}
+
+func (dc *DaemonClient) GetConnectionsContext(ctx context.Context) (response ConnectionsResponse, err error) {
+	return response, dc.jsonRequest(ctx, "get_connections", nil, &response)
 }
 
 func (dc *DaemonClient) GetInfo() (response InfoResponse, err error) {
-	return response, dc.jsonRequest("get_info", nil, &response)
+	return dc.GetInfoContext(context.Background())
+}
+
+func (dc *DaemonClient) GetInfoContext(ctx context.Context) (response InfoResponse, err error) {
+	return response, dc.jsonRequest(ctx, "get_info", nil, &response)
 }
 
 func (dc *DaemonClient) HardForkInfo() (response HardForkInfoResponse, err error) {
-	return response, dc.jsonRequest("hard_fork_info", nil, &response)
+	return dc.HardForkInfoContext(context.Background())
+}
+
+func (dc *DaemonClient) HardForkInfoContext(ctx context.Context) (response HardForkInfoResponse, err error) {
+	return response, dc.jsonRequest(ctx, "hard_fork_info", nil, &response)
 }
 
 func (dc *DaemonClient) SetBans(bans []Ban) (response StatusResponse, err error) {
+	return dc.SetBansContext(context.Background(), bans)
+}
+
+func (dc *DaemonClient) SetBansContext(ctx context.Context, bans []Ban) (response StatusResponse, err error) {
 	type Params struct {
 		Bans []Ban `json:"bans"`
 	}
 
 	params := Params{Bans: bans}
-	return response, dc.jsonRequest("set_bans", params, &response)
+	return response, dc.jsonRequest(ctx, "set_bans", params, &response)
 }
 
 func (dc *DaemonClient) GetBans() (response BansResponse, err error) {
-	return response, dc.jsonRequest("get_bans", nil, &response)
+	return dc.GetBansContext(context.Background())
+}
+
+func (dc *DaemonClient) GetBansContext(ctx context.Context) (response BansResponse, err error) {
+	return response, dc.jsonRequest(ctx, "get_bans", nil, &response)
 }
 
 func (dc *DaemonClient) FlushTxpool(txids []string) (response StatusResponse, err error) {
+	return dc.FlushTxpoolContext(context.Background(), txids)
+}
+
+func (dc *DaemonClient) FlushTxpoolContext(ctx context.Context, txids []string) (response StatusResponse, err error) {
 	type Params struct {
 		TxIDs []string `json:"txids"`
 	}
 
 	params := Params{TxIDs: txids}
-	return response, dc.jsonRequest("flush_txpool", params, &response)
+	return response, dc.jsonRequest(ctx, "flush_txpool", params, &response)
 }
 
 func (dc *DaemonClient) GetOutputHistogram(amounts []uint, minCount uint, maxCount uint, unlocked bool, recentCutoff uint) (response OutputHistogramResponse, err error) {
+	return dc.GetOutputHistogramContext(context.Background(), amounts, minCount, maxCount, unlocked, recentCutoff)
+}
+
+func (dc *DaemonClient) GetOutputHistogramContext(ctx context.Context, amounts []uint, minCount uint, maxCount uint, unlocked bool, recentCutoff uint) (response OutputHistogramResponse, err error) {
 	type Params struct {
 		Amounts      []uint `json:"amounts"`
 		MinCount     uint   `json:"min_count"`
@@ -566,73 +1083,307 @@ func (dc *DaemonClient) GetOutputHistogram(amounts []uint, minCount uint, maxCou
 	}
 
 	params := Params{Amounts: amounts, MinCount: minCount, MaxCount: maxCount, Unlocked: unlocked, RecentCutoff: recentCutoff}
-	return response, dc.jsonRequest("get_output_histogram", params, &response)
+	return response, dc.jsonRequest(ctx, "get_output_histogram", params, &response)
 }
 
 func (dc *DaemonClient) GetVersion() (response VersionResponse, err error) {
-	return response, dc.jsonRequest("get_version", nil, &response)
+	return dc.GetVersionContext(context.Background())
+}
+
+func (dc *DaemonClient) GetVersionContext(ctx context.Context) (response VersionResponse, err error) {
+	return response, dc.jsonRequest(ctx, "get_version", nil, &response)
 }
 
 func (dc *DaemonClient) GetCoinbaseTxSum(height uint, count uint) (response CoinbaseTxSumResponse, err error) {
+	return dc.GetCoinbaseTxSumContext(context.Background(), height, count)
+}
+
+func (dc *DaemonClient) GetCoinbaseTxSumContext(ctx context.Context, height uint, count uint) (response CoinbaseTxSumResponse, err error) {
 	type Params struct {
 		Height uint `json:"height"`
 		Count  uint `json:"count"`
 	}
 
 	params := Params{Height: height, Count: count}
-	return response, dc.jsonRequest("get_coinbase_tx_sum", params, &response)
+	return response, dc.jsonRequest(ctx, "get_coinbase_tx_sum", params, &response)
 }
 
 func (dc *DaemonClient) GetFeeEstimate(graceBlocks uint) (response FeeEstimateResponse, err error) {
+	return dc.GetFeeEstimateContext(context.Background(), graceBlocks)
+}
+
+func (dc *DaemonClient) GetFeeEstimateContext(ctx context.Context, graceBlocks uint) (response FeeEstimateResponse, err error) {
 	type Params struct {
 		GraceBlocks uint `json:"grace_blocks"`
 	}
 
 	params := Params{GraceBlocks: graceBlocks}
-	return response, dc.jsonRequest("get_fee_estimate", params, &response)
+	return response, dc.jsonRequest(ctx, "get_fee_estimate", params, &response)
 }
 
 func (dc *DaemonClient) GetAlternateChains() (response AlternateChainsResponse, err error) {
-	return response, dc.jsonRequest("get_alternate_chains", nil, &response)
+	return dc.GetAlternateChainsContext(context.Background())
+}
+
+func (dc *DaemonClient) GetAlternateChainsContext(ctx context.Context) (response AlternateChainsResponse, err error) {
+	return response, dc.jsonRequest(ctx, "get_alternate_chains", nil, &response)
 }
 
 func (dc *DaemonClient) RelayTx(txids []string) (response StatusResponse, err error) {
+	return dc.RelayTxContext(context.Background(), txids)
+}
+
+func (dc *DaemonClient) RelayTxContext(ctx context.Context, txids []string) (response StatusResponse, err error) {
 	type Params struct {
 		TxIDs []string `json:"txids"`
 	}
 
 	params := Params{TxIDs: txids}
-	return response, dc.jsonRequest("relay_tx", params, &response)
+	return response, dc.jsonRequest(ctx, "relay_tx", params, &response)
 }
 
 func (dc *DaemonClient) SyncInfo() (response SyncInfoResponse, err error) {
-	return response, dc.jsonRequest("sync_info", nil, &response)
+	return dc.SyncInfoContext(context.Background())
+}
+
+func (dc *DaemonClient) SyncInfoContext(ctx context.Context) (response SyncInfoResponse, err error) {
+	return response, dc.jsonRequest(ctx, "sync_info", nil, &response)
 }
 
 func (dc *DaemonClient) GetTxpoolBacklog() (response TxpoolBacklogResponse, err error) {
-	return response, dc.jsonRequest("get_txpool_backlog", nil, &response)
+	return dc.GetTxpoolBacklogContext(context.Background())
+}
+
+func (dc *DaemonClient) GetTxpoolBacklogContext(ctx context.Context) (response TxpoolBacklogResponse, err error) {
+	return response, dc.jsonRequest(ctx, "get_txpool_backlog", nil, &response)
 }
 
 func (dc *DaemonClient) GetOutputDistribution(amounts []uint, cumulative bool, fromHeight uint, toHeight uint) (response OutputDistributionResponse, err error) {
+	return dc.GetOutputDistributionContext(context.Background(), amounts, cumulative, fromHeight, toHeight)
+}
+
+func (dc *DaemonClient) GetOutputDistributionContext(ctx context.Context, amounts []uint, cumulative bool, fromHeight uint, toHeight uint) (response OutputDistributionResponse, err error) {
 	type Params struct {
 		Amounts    []uint `json:"amounts"`
 		Cumulative bool   `json:"cumulative"`
 		FromHeight uint   `json:"from_height"`
 		ToHeight   uint   `json:"to_height"`
+		Binary     bool   `json:"binary"`
+	}
+
+	// The daemon returns distributions as epee blobs unless binary is false.
+	params := Params{Amounts: amounts, Cumulative: cumulative, FromHeight: fromHeight, ToHeight: toHeight, Binary: false}
+	return response, dc.jsonRequest(ctx, "get_output_distribution", params, &response)
+}
+
+func (dc *DaemonClient) GetMinerData() (response MinerDataResponse, err error) {
+	return dc.GetMinerDataContext(context.Background())
+}
+
+func (dc *DaemonClient) GetMinerDataContext(ctx context.Context) (response MinerDataResponse, err error) {
+	type Params struct{}
+
+	params := Params{}
+	return response, dc.jsonRequest(ctx, "get_miner_data", params, &response)
+}
+
+func (dc *DaemonClient) CalcPow(majorVersion uint, height uint, blockBlob string, seedHash string) (response string, err error) {
+	return dc.CalcPowContext(context.Background(), majorVersion, height, blockBlob, seedHash)
+}
+
+func (dc *DaemonClient) CalcPowContext(ctx context.Context, majorVersion uint, height uint, blockBlob string, seedHash string) (response string, err error) {
+	type Params struct {
+		MajorVersion uint   `json:"major_version"`
+		Height       uint   `json:"height"`
+		BlockBlob    string `json:"block_blob"`
+		SeedHash     string `json:"seed_hash"`
 	}
 
-	params := Params{Amounts: amounts, Cumulative: cumulative, FromHeight: fromHeight, ToHeight: toHeight}
-	return response, dc.jsonRequest("get_output_distribution", params, &response)
+	params := Params{MajorVersion: majorVersion, Height: height, BlockBlob: blockBlob, SeedHash: seedHash}
+	return response, dc.jsonRequest(ctx, "calc_pow", params, &response)
+}
+
+func (dc *DaemonClient) AddAuxPow(blockTemplateBlob string, auxPow []AuxPow) (response AddAuxPowResponse, err error) {
+	return dc.AddAuxPowContext(context.Background(), blockTemplateBlob, auxPow)
+}
+
+func (dc *DaemonClient) AddAuxPowContext(ctx context.Context, blockTemplateBlob string, auxPow []AuxPow) (response AddAuxPowResponse, err error) {
+	type Params struct {
+		BlockTemplateBlob string   `json:"blocktemplate_blob"`
+		AuxPow            []AuxPow `json:"aux_pow"`
+	}
+
+	params := Params{BlockTemplateBlob: blockTemplateBlob, AuxPow: auxPow}
+	return response, dc.jsonRequest(ctx, "add_aux_pow", params, &response)
+}
+
+func (dc *DaemonClient) FlushCache(badTxs bool, badBlocks bool) (response StatusResponse, err error) {
+	return dc.FlushCacheContext(context.Background(), badTxs, badBlocks)
+}
+
+func (dc *DaemonClient) FlushCacheContext(ctx context.Context, badTxs bool, badBlocks bool) (response StatusResponse, err error) {
+	type Params struct {
+		BadTxs    bool `json:"bad_txs"`
+		BadBlocks bool `json:"bad_blocks"`
+	}
+
+	params := Params{BadTxs: badTxs, BadBlocks: badBlocks}
+	return response, dc.jsonRequest(ctx, "flush_cache", params, &response)
+}
+
+func (dc *DaemonClient) PruneBlockchain(check bool) (response PruneBlockchainResponse, err error) {
+	return dc.PruneBlockchainContext(context.Background(), check)
+}
+
+func (dc *DaemonClient) PruneBlockchainContext(ctx context.Context, check bool) (response PruneBlockchainResponse, err error) {
+	type Params struct {
+		Check bool `json:"check"`
+	}
+
+	params := Params{Check: check}
+	return response, dc.jsonRequest(ctx, "prune_blockchain", params, &response)
+}
+
+func (dc *DaemonClient) GetTxidsLoose(txidTemplate string, numMatchingBits uint) (response TxidsLooseResponse, err error) {
+	return dc.GetTxidsLooseContext(context.Background(), txidTemplate, numMatchingBits)
+}
+
+func (dc *DaemonClient) GetTxidsLooseContext(ctx context.Context, txidTemplate string, numMatchingBits uint) (response TxidsLooseResponse, err error) {
+	type Params struct {
+		TxidTemplate    string `json:"txid_template"`
+		NumMatchingBits uint   `json:"num_matching_bits"`
+	}
+
+	params := Params{TxidTemplate: txidTemplate, NumMatchingBits: numMatchingBits}
+	return response, dc.jsonRequest(ctx, "get_txids_loose", params, &response)
+}
+
+func (dc *DaemonClient) GenerateBlocks(amountOfBlocks uint, walletAddress string, prevBlock string, startingNonce uint) (response GenerateBlocksResponse, err error) {
+	return dc.GenerateBlocksContext(context.Background(), amountOfBlocks, walletAddress, prevBlock, startingNonce)
+}
+
+func (dc *DaemonClient) GenerateBlocksContext(ctx context.Context, amountOfBlocks uint, walletAddress string, prevBlock string, startingNonce uint) (response GenerateBlocksResponse, err error) {
+	type Params struct {
+		AmountOfBlocks uint   `json:"amount_of_blocks"`
+		WalletAddress  string `json:"wallet_address"`
+		PrevBlock      string `json:"prev_block,omitempty"`
+		StartingNonce  uint   `json:"starting_nonce"`
+	}
+
+	params := Params{AmountOfBlocks: amountOfBlocks, WalletAddress: walletAddress, PrevBlock: prevBlock, StartingNonce: startingNonce}
+	return response, dc.jsonRequest(ctx, "generateblocks", params, &response)
+}
+
+func (dc *DaemonClient) Banned(address string) (response BannedResponse, err error) {
+	return dc.BannedContext(context.Background(), address)
+}
+
+func (dc *DaemonClient) BannedContext(ctx context.Context, address string) (response BannedResponse, err error) {
+	type Params struct {
+		Address string `json:"address"`
+	}
+
+	params := Params{Address: address}
+	return response, dc.jsonRequest(ctx, "banned", params, &response)
+}
+
+func (dc *DaemonClient) RPCAccessInfo(client string) (response RPCAccessInfoResponse, err error) {
+	return dc.RPCAccessInfoContext(context.Background(), client)
+}
+
+func (dc *DaemonClient) RPCAccessInfoContext(ctx context.Context, client string) (response RPCAccessInfoResponse, err error) {
+	type Params struct {
+		Client string `json:"client"`
+	}
+
+	params := Params{Client: client}
+	return response, dc.jsonRequest(ctx, "rpc_access_info", params, &response)
+}
+
+func (dc *DaemonClient) RPCAccessSubmitNonce(client string, nonce uint, cookie uint) (response RPCAccessResponse, err error) {
+	return dc.RPCAccessSubmitNonceContext(context.Background(), client, nonce, cookie)
+}
+
+func (dc *DaemonClient) RPCAccessSubmitNonceContext(ctx context.Context, client string, nonce uint, cookie uint) (response RPCAccessResponse, err error) {
+	type Params struct {
+		Client string `json:"client"`
+		Nonce  uint   `json:"nonce"`
+		Cookie uint   `json:"cookie"`
+	}
+
+	params := Params{Client: client, Nonce: nonce, Cookie: cookie}
+	return response, dc.jsonRequest(ctx, "rpc_access_submit_nonce", params, &response)
+}
+
+func (dc *DaemonClient) RPCAccessPay(client string, payingFor string, payment uint) (response RPCAccessResponse, err error) {
+	return dc.RPCAccessPayContext(context.Background(), client, payingFor, payment)
+}
+
+func (dc *DaemonClient) RPCAccessPayContext(ctx context.Context, client string, payingFor string, payment uint) (response RPCAccessResponse, err error) {
+	type Params struct {
+		Client    string `json:"client"`
+		PayingFor string `json:"paying_for"`
+		Payment   uint   `json:"payment"`
+	}
+
+	params := Params{Client: client, PayingFor: payingFor, Payment: payment}
+	return response, dc.jsonRequest(ctx, "rpc_access_pay", params, &response)
+}
+
+func (dc *DaemonClient) RPCAccessTracking(clear bool) (response RPCAccessTrackingResponse, err error) {
+	return dc.RPCAccessTrackingContext(context.Background(), clear)
+}
+
+func (dc *DaemonClient) RPCAccessTrackingContext(ctx context.Context, clear bool) (response RPCAccessTrackingResponse, err error) {
+	type Params struct {
+		Clear bool `json:"clear"`
+	}
+
+	params := Params{Clear: clear}
+	return response, dc.jsonRequest(ctx, "rpc_access_tracking", params, &response)
+}
+
+func (dc *DaemonClient) RPCAccessData() (response RPCAccessDataResponse, err error) {
+	return dc.RPCAccessDataContext(context.Background())
+}
+
+func (dc *DaemonClient) RPCAccessDataContext(ctx context.Context) (response RPCAccessDataResponse, err error) {
+	type Params struct{}
+
+	params := Params{}
+	return response, dc.jsonRequest(ctx, "rpc_access_data", params, &response)
+}
+
+func (dc *DaemonClient) RPCAccessAccount(client string, deltaBalance int) (response RPCAccessAccountResponse, err error) {
+	return dc.RPCAccessAccountContext(context.Background(), client, deltaBalance)
+}
+
+func (dc *DaemonClient) RPCAccessAccountContext(ctx context.Context, client string, deltaBalance int) (response RPCAccessAccountResponse, err error) {
+	type Params struct {
+		Client       string `json:"client"`
+		DeltaBalance int    `json:"delta_balance"`
+	}
+
+	params := Params{Client: client, DeltaBalance: deltaBalance}
+	return response, dc.jsonRequest(ctx, "rpc_access_account", params, &response)
 }
 
 func (dc *DaemonClient) GetHeight() (response HeightResponse, err error) {
+	return dc.GetHeightContext(context.Background())
+}
+
+func (dc *DaemonClient) GetHeightContext(ctx context.Context) (response HeightResponse, err error) {
 	type Params struct{}
 
 	params := Params{}
-	return response, dc.rpcRequest("/get_height", params, &response)
+	return response, dc.rpcRequest(ctx, "/get_height", params, &response)
 }
 
 func (dc *DaemonClient) GetTransactions(txs_hashes []string, decode_as_json bool, prune bool) (response TransactionsResponse, err error) {
+	return dc.GetTransactionsContext(context.Background(), txs_hashes, decode_as_json, prune)
+}
+
+func (dc *DaemonClient) GetTransactionsContext(ctx context.Context, txs_hashes []string, decode_as_json bool, prune bool) (response TransactionsResponse, err error) {
 	type Params struct {
 		TxsHashes    []string `json:"txs_hashes"`
 		DecodeAsJSON bool     `json:"decode_as_json"`
@@ -640,36 +1391,52 @@ func (dc *DaemonClient) GetTransactions(txs_hashes []string, decode_as_json bool
 	}
 
 	params := Params{TxsHashes: txs_hashes, DecodeAsJSON: decode_as_json, Prune: prune}
-	return response, dc.rpcRequest("/get_transactions", params, &response)
+	return response, dc.rpcRequest(ctx, "/get_transactions", params, &response)
 }
 
 func (dc *DaemonClient) GetAltBlocksHashes() (response AltBlocksHashesResponse, err error) {
+	return dc.GetAltBlocksHashesContext(context.Background())
+}
+
+func (dc *DaemonClient) GetAltBlocksHashesContext(ctx context.Context) (response AltBlocksHashesResponse, err error) {
 	type Params struct{}
 
 	params := Params{}
-	return response, dc.rpcRequest("/get_alt_blocks_hashes", params, &response)
+	return response, dc.rpcRequest(ctx, "/get_alt_blocks_hashes", params, &response)
 }
 
 func (dc *DaemonClient) IsKeyImageSpent(keyImages []string) (response IsKeyImageSpentResponse, err error) {
+	return dc.IsKeyImageSpentContext(context.Background(), keyImages)
+}
+
+func (dc *DaemonClient) IsKeyImageSpentContext(ctx context.Context, keyImages []string) (response IsKeyImageSpentResponse, err error) {
 	type Params struct {
 		KeyImages []string `json:"key_images"`
 	}
 
 	params := Params{KeyImages: keyImages}
-	return response, dc.rpcRequest("/is_key_image_spent", params, &response)
+	return response, dc.rpcRequest(ctx, "/is_key_image_spent", params, &response)
 }
 
 func (dc *DaemonClient) SendRawTransaction(txAsHex string, doNotRelay bool) (response SendRawTransactionResponse, err error) {
+	return dc.SendRawTransactionContext(context.Background(), txAsHex, doNotRelay)
+}
+
+func (dc *DaemonClient) SendRawTransactionContext(ctx context.Context, txAsHex string, doNotRelay bool) (response SendRawTransactionResponse, err error) {
 	type Params struct {
 		TxAsHex    string `json:"txAsHex"`
 		DoNotRelay bool   `json:"doNotRelay"`
 	}
 
 	params := Params{TxAsHex: txAsHex, DoNotRelay: doNotRelay}
-	return response, dc.rpcRequest("/send_raw_transaction", params, &response)
+	return response, dc.rpcRequest(ctx, "/send_raw_transaction", params, &response)
 }
 
 func (dc *DaemonClient) StartMining(doBackgroundMining bool, ignoreBattery bool, minerAddress string, threadsCount uint) (response StatusResponse, err error) {
+	return dc.StartMiningContext(context.Background(), doBackgroundMining, ignoreBattery, minerAddress, threadsCount)
+}
+
+func (dc *DaemonClient) StartMiningContext(ctx context.Context, doBackgroundMining bool, ignoreBattery bool, minerAddress string, threadsCount uint) (response StatusResponse, err error) {
 	type Params struct {
 		DoBackgroundMining bool   `json:"do_background_mining"`
 		IgnoreBattery      bool   `json:"ignore_battery"`
@@ -678,140 +1445,274 @@ func (dc *DaemonClient) StartMining(doBackgroundMining bool, ignoreBattery bool,
 	}
 
 	params := Params{DoBackgroundMining: doBackgroundMining, IgnoreBattery: ignoreBattery, MinerAddress: minerAddress, ThreadsCount: threadsCount}
-	return response, dc.rpcRequest("/start_mining", params, &response)
+	return response, dc.rpcRequest(ctx, "/start_mining", params, &response)
 }
 
 func (dc *DaemonClient) StopMining() (response StatusResponse, err error) {
+	return dc.StopMiningContext(context.Background())
+}
+
+func (dc *DaemonClient) StopMiningContext(ctx context.Context) (response StatusResponse, err error) {
 	type Params struct{}
 
 	params := Params{}
-	return response, dc.rpcRequest("/stop_mining", params, &response)
+	return response, dc.rpcRequest(ctx, "/stop_mining", params, &response)
 }
 
 func (dc *DaemonClient) MiningStatus() (response MiningStatusResponse, err error) {
+	return dc.MiningStatusContext(context.Background())
+}
+
+func (dc *DaemonClient) MiningStatusContext(ctx context.Context) (response MiningStatusResponse, err error) {
 	type Params struct{}
 
 	params := Params{}
-	return response, dc.rpcRequest("/mining_status", params, &response)
+	return response, dc.rpcRequest(ctx, "/mining_status", params, &response)
 }
 
 func (dc *DaemonClient) SaveBC() (response StatusResponse, err error) {
+	return dc.SaveBCContext(context.Background())
+}
+
+func (dc *DaemonClient) SaveBCContext(ctx context.Context) (response StatusResponse, err error) {
 	type Params struct{}
 
 	params := Params{}
-	return response, dc.rpcRequest("/save_bc", params, &response)
+	return response, dc.rpcRequest(ctx, "/save_bc", params, &response)
 }
 
 func (dc *DaemonClient) GetPeerList() (response PeerListResponse, err error) {
+	return dc.GetPeerListContext(context.Background())
+}
+
+func (dc *DaemonClient) GetPeerListContext(ctx context.Context) (response PeerListResponse, err error) {
 	type Params struct{}
 
 	params := Params{}
-	return response, dc.rpcRequest("/get_peer_list", params, &response)
+	return response, dc.rpcRequest(ctx, "/get_peer_list", params, &response)
 }
 
 func (dc *DaemonClient) SetLogHashRate(visible bool) (response StatusResponse, err error) {
+	return dc.SetLogHashRateContext(context.Background(), visible)
+}
+
+func (dc *DaemonClient) SetLogHashRateContext(ctx context.Context, visible bool) (response StatusResponse, err error) {
 	type Params struct {
 		Visible bool `json:"visible"`
 	}
 
 	params := Params{Visible: visible}
-	return response, dc.rpcRequest("/set_log_hash_rate", params, &response)
+	return response, dc.rpcRequest(ctx, "/set_log_hash_rate", params, &response)
 }
 
 func (dc *DaemonClient) SetLogLevel(level uint) (response StatusResponse, err error) {
+	return dc.SetLogLevelContext(context.Background(), level)
+}
+
+func (dc *DaemonClient) SetLogLevelContext(ctx context.Context, level uint) (response StatusResponse, err error) {
 	type Params struct {
 		Level uint `json:"level"`
 	}
 
 	params := Params{Level: level}
-	return response, dc.rpcRequest("/set_log_level", params, &response)
+	return response, dc.rpcRequest(ctx, "/set_log_level", params, &response)
 }
 
 func (dc *DaemonClient) SetLogCategories(categories string) (response LogCategoriesResponse, err error) {
+	return dc.SetLogCategoriesContext(context.Background(), categories)
+}
+
+func (dc *DaemonClient) SetLogCategoriesContext(ctx context.Context, categories string) (response LogCategoriesResponse, err error) {
 	type Params struct {
 		Categories string `json:"categories"`
 	}
 
 	params := Params{Categories: categories}
-	return response, dc.rpcRequest("/set_log_categories", params, &response)
+	return response, dc.rpcRequest(ctx, "/set_log_categories", params, &response)
 }
 
 func (dc *DaemonClient) GetTransactionPool() (response TransactionPoolResponse, err error) {
+	return dc.GetTransactionPoolContext(context.Background())
+}
+
+func (dc *DaemonClient) GetTransactionPoolContext(ctx context.Context) (response TransactionPoolResponse, err error) {
 	type Params struct{}
 
 	params := Params{}
-	return response, dc.rpcRequest("/get_transaction_pool", params, &response)
+	return response, dc.rpcRequest(ctx, "/get_transaction_pool", params, &response)
 }
 
 func (dc *DaemonClient) GetTransactionPoolStats() (response TransactionPoolStatsResponse, err error) {
+	return dc.GetTransactionPoolStatsContext(context.Background())
+}
+
+func (dc *DaemonClient) GetTransactionPoolStatsContext(ctx context.Context) (response TransactionPoolStatsResponse, err error) {
 	type Params struct{}
 
 	params := Params{}
-	return response, dc.rpcRequest("/get_transaction_pool_stats", params, &response)
+	return response, dc.rpcRequest(ctx, "/get_transaction_pool_stats", params, &response)
 }
 
 func (dc *DaemonClient) StopDaemon() (response StatusResponse, err error) {
+	return dc.StopDaemonContext(context.Background())
+}
+
+func (dc *DaemonClient) StopDaemonContext(ctx context.Context) (response StatusResponse, err error) {
 	type Params struct{}
 
 	params := Params{}
-	return response, dc.rpcRequest("/stop_daemon", params, &response)
+	return response, dc.rpcRequest(ctx, "/stop_daemon", params, &response)
 }
 
 func (dc *DaemonClient) GetLimit() (response LimitResponse, err error) {
+	return dc.GetLimitContext(context.Background())
+}
+
+func (dc *DaemonClient) GetLimitContext(ctx context.Context) (response LimitResponse, err error) {
 	type Params struct{}
 
 	params := Params{}
-	return response, dc.rpcRequest("/get_limit", params, &response)
+	return response, dc.rpcRequest(ctx, "/get_limit", params, &response)
 }
 
 func (dc *DaemonClient) SetLimit(limitDown int, limitUp int) (response LimitResponse, err error) {
+	return dc.SetLimitContext(context.Background(), limitDown, limitUp)
+}
+
+func (dc *DaemonClient) SetLimitContext(ctx context.Context, limitDown int, limitUp int) (response LimitResponse, err error) {
 	type Params struct {
 		LimitDown int `json:"limit_down"`
 		LimitUp   int `json:"limit_up"`
 	}
 
 	params := Params{LimitDown: limitDown, LimitUp: limitUp}
-	return response, dc.rpcRequest("/set_limit", params, &response)
+	return response, dc.rpcRequest(ctx, "/set_limit", params, &response)
 }
 
 func (dc *DaemonClient) OutPeers(outPeers uint) (response StatusResponse, err error) {
+	return dc.OutPeersContext(context.Background(), outPeers)
+}
+
+func (dc *DaemonClient) OutPeersContext(ctx context.Context, outPeers uint) (response StatusResponse, err error) {
 	type Params struct {
 		OutPeers uint `json:"out_peers"`
 	}
 
 	params := Params{OutPeers: outPeers}
-	return response, dc.rpcRequest("/out_peers", params, &response)
+	return response, dc.rpcRequest(ctx, "/out_peers", params, &response)
 }
 
 func (dc *DaemonClient) InPeers(inPeers uint) (response StatusResponse, err error) {
+	return dc.InPeersContext(context.Background(), inPeers)
+}
+
+func (dc *DaemonClient) InPeersContext(ctx context.Context, inPeers uint) (response StatusResponse, err error) {
 	type Params struct {
 		InPeers uint `json:"in_peers"`
 	}
 
 	params := Params{InPeers: inPeers}
-	return response, dc.rpcRequest("/out_peers", params, &response)
+	return response, dc.rpcRequest(ctx, "/in_peers", params, &response)
 }
 
 func (dc *DaemonClient) StartSaveGraph() (response StatusResponse, err error) {
+	return dc.StartSaveGraphContext(context.Background())
+}
+
+func (dc *DaemonClient) StartSaveGraphContext(ctx context.Context) (response StatusResponse, err error) {
 	type Params struct{}
 
 	params := Params{}
-	return response, dc.rpcRequest("/start_save_graph", params, &response)
+	return response, dc.rpcRequest(ctx, "/start_save_graph", params, &response)
 }
 
 func (dc *DaemonClient) StopSaveGraph() (response StatusResponse, err error) {
+	return dc.StopSaveGraphContext(context.Background())
+}
+
+func (dc *DaemonClient) StopSaveGraphContext(ctx context.Context) (response StatusResponse, err error) {
 	type Params struct{}
 
 	params := Params{}
-	return response, dc.rpcRequest("/stop_save_graph", params, &response)
+	return response, dc.rpcRequest(ctx, "/stop_save_graph", params, &response)
 }
 
 func (dc *DaemonClient) Update(command string, path string) (response UpdateResponse, err error) {
+	return dc.UpdateContext(context.Background(), command, path)
+}
+
+func (dc *DaemonClient) UpdateContext(ctx context.Context, command string, path string) (response UpdateResponse, err error) {
 	type Params struct {
 		Command string `json:"command"`
 		Path    string `json:"path"`
 	}
 
 	params := Params{Command: command, Path: path}
-	return response, dc.rpcRequest("/update", params, &response)
+	return response, dc.rpcRequest(ctx, "/update", params, &response)
+}
+
+func (dc *DaemonClient) SetBootstrapDaemon(address string, username string, password string, proxy string) (response StatusResponse, err error) {
+	return dc.SetBootstrapDaemonContext(context.Background(), address, username, password, proxy)
+}
+
+func (dc *DaemonClient) SetBootstrapDaemonContext(ctx context.Context, address string, username string, password string, proxy string) (response StatusResponse, err error) {
+	type Params struct {
+		Address  string `json:"address"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Proxy    string `json:"proxy"`
+	}
+
+	params := Params{Address: address, Username: username, Password: password, Proxy: proxy}
+	return response, dc.rpcRequest(ctx, "/set_bootstrap_daemon", params, &response)
+}
+
+func (dc *DaemonClient) GetPublicNodes(gray bool, white bool, includeBlocked bool) (response PublicNodesResponse, err error) {
+	return dc.GetPublicNodesContext(context.Background(), gray, white, includeBlocked)
+}
+
+func (dc *DaemonClient) GetPublicNodesContext(ctx context.Context, gray bool, white bool, includeBlocked bool) (response PublicNodesResponse, err error) {
+	type Params struct {
+		Gray           bool `json:"gray"`
+		White          bool `json:"white"`
+		IncludeBlocked bool `json:"include_blocked"`
+	}
+
+	params := Params{Gray: gray, White: white, IncludeBlocked: includeBlocked}
+	return response, dc.rpcRequest(ctx, "/get_public_nodes", params, &response)
+}
+
+func (dc *DaemonClient) GetNetStats() (response NetStatsResponse, err error) {
+	return dc.GetNetStatsContext(context.Background())
+}
+
+func (dc *DaemonClient) GetNetStatsContext(ctx context.Context) (response NetStatsResponse, err error) {
+	type Params struct{}
+
+	params := Params{}
+	return response, dc.rpcRequest(ctx, "/get_net_stats", params, &response)
+}
+
+func (dc *DaemonClient) GetTransactionPoolHashes() (response TransactionPoolHashesResponse, err error) {
+	return dc.GetTransactionPoolHashesContext(context.Background())
+}
+
+func (dc *DaemonClient) GetTransactionPoolHashesContext(ctx context.Context) (response TransactionPoolHashesResponse, err error) {
+	type Params struct{}
+
+	params := Params{}
+	return response, dc.rpcRequest(ctx, "/get_transaction_pool_hashes", params, &response)
+}
+
+func (dc *DaemonClient) PopBlocks(nblocks uint) (response PopBlocksResponse, err error) {
+	return dc.PopBlocksContext(context.Background(), nblocks)
+}
+
+func (dc *DaemonClient) PopBlocksContext(ctx context.Context, nblocks uint) (response PopBlocksResponse, err error) {
+	type Params struct {
+		NBlocks uint `json:"nblocks"`
+	}
+
+	params := Params{NBlocks: nblocks}
+	return response, dc.rpcRequest(ctx, "/pop_blocks", params, &response)
 }
@@ -1,7 +1,9 @@
 package xmrrpc
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -21,23 +23,40 @@ var statusErrorResponse = &jsonRPCError{
 
 type daemonClientTestSuite struct {
 	suite.Suite
-	ts *httptest.Server
+	ts      *httptest.Server
+	uri     string
+	params  map[string]interface{}
+	results map[string]interface{}
 }
 
 func (s *daemonClientTestSuite) SetupTest() {
+	s.results = map[string]interface{}{}
 	s.ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var res []byte
 
+		s.uri = r.RequestURI
+		s.params = nil
 		switch r.RequestURI {
 		case "/json_rpc":
 			req := &jsonRPCRequest{}
 			err := json.NewDecoder(r.Body).Decode(&req)
 			if assert.NoError(s.T(), err) {
+				s.params, _ = req.Params.(map[string]interface{})
+				if result, ok := s.results[req.Method]; ok {
+					res, _ = json.Marshal(result)
+					res, _ = json.Marshal(&jsonRPCResponse{ID: req.ID, Version: "2.0", Result: res})
+					break
+				}
+
 				switch req.Method {
 				case "on_get_block_hash":
 					res, _ = json.Marshal("e22cf75f39ae720e8b71b3d120a5ac03f0db50bba6379e2850975b4859190bc6")
 					res, _ = json.Marshal(&jsonRPCResponse{ID: req.ID, Version: "2.0", Result: res})
 					break
+				case "calc_pow":
+					res, _ = json.Marshal("d0402d6834e26fb94a9ce38c6424d27d2069896a9b8b1ce685d79936bca6e0a8")
+					res, _ = json.Marshal(&jsonRPCResponse{ID: req.ID, Version: "2.0", Result: res})
+					break
 				case "submit_block":
 					res, _ = json.Marshal(&jsonRPCResponse{ID: req.ID, Version: "2.0", Error: *statusErrorResponse})
 					break
@@ -49,6 +68,12 @@ func (s *daemonClientTestSuite) SetupTest() {
 			}
 			break
 		default:
+			json.NewDecoder(r.Body).Decode(&s.params)
+			if result, ok := s.results[r.RequestURI]; ok {
+				res, _ = json.Marshal(result)
+				break
+			}
+
 			res, _ = json.Marshal(statusOkResponse)
 			break
 		}
@@ -90,6 +115,17 @@ func (s *daemonClientTestSuite) TestSubmitBlock() {
 	}
 }
 
+func (s *daemonClientTestSuite) TestSubmitBlockError() {
+	_, err := NewDaemonClient(s.ts.URL, "username", "password").SubmitBlock("0707e6bdfedc0...")
+	if assert.True(s.T(), errors.Is(err, ErrBlockNotAccepted)) {
+		var rpcErr *RPCError
+		if assert.True(s.T(), errors.As(err, &rpcErr)) {
+			assert.Equal(s.T(), -7, rpcErr.Code)
+			assert.Equal(s.T(), "submit_block", rpcErr.Method)
+		}
+	}
+}
+
 func (s *daemonClientTestSuite) TestGetLastBlockHeader() {
 	res, err := NewDaemonClient(s.ts.URL, "username", "password").GetLastBlockHeader()
 	if assert.NoError(s.T(), err) {
@@ -139,6 +175,27 @@ func (s *daemonClientTestSuite) TestGetInfo() {
 	}
 }
 
+func (s *daemonClientTestSuite) TestGetInfoContext() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewDaemonClient(s.ts.URL, "username", "password").GetInfoContext(ctx)
+	assert.Error(s.T(), err)
+
+	res, err := NewDaemonClient(s.ts.URL, "username", "password").GetInfoContext(context.Background())
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), "OK", res.Status)
+	}
+}
+
+func (s *daemonClientTestSuite) TestGetHeightContext() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewDaemonClient(s.ts.URL, "username", "password").GetHeightContext(ctx)
+	assert.Error(s.T(), err)
+}
+
 func (s *daemonClientTestSuite) TestHardForkInfo() {
 	res, err := NewDaemonClient(s.ts.URL, "username", "password").HardForkInfo()
 	if assert.NoError(s.T(), err) {
@@ -230,6 +287,173 @@ func (s *daemonClientTestSuite) TestGetOutputDistribution() {
 	}
 }
 
+func (s *daemonClientTestSuite) TestGetMinerData() {
+	s.results["get_miner_data"] = &MinerDataResponse{
+		Difficulty:   "0x3c2d8b0d1a",
+		Height:       2731375,
+		MajorVersion: 16,
+		PrevID:       "ab14a0bcd2a0a8f53b81f3d5da5b8e19d1dfd3d19e2e4c0f0d8f3a62e4e2d9a1",
+		TxBacklog:    []TxBacklogEntry{{Fee: 30680000, ID: "9aa5e2d1a1f3", Weight: 1535}},
+		Status:       "OK",
+	}
+
+	res, err := NewDaemonClient(s.ts.URL, "username", "password").GetMinerData()
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), "OK", res.Status)
+		assert.Equal(s.T(), "0x3c2d8b0d1a", res.Difficulty)
+		assert.Equal(s.T(), uint(2731375), res.Height)
+		assert.Equal(s.T(), uint(16), res.MajorVersion)
+		assert.Equal(s.T(), []TxBacklogEntry{{Fee: 30680000, ID: "9aa5e2d1a1f3", Weight: 1535}}, res.TxBacklog)
+	}
+}
+
+func (s *daemonClientTestSuite) TestCalcPow() {
+	res, err := NewDaemonClient(s.ts.URL, "username", "password").CalcPow(16, 2000000, "0e0ed286da8006", "ab14a0bcd2a0")
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), "d0402d6834e26fb94a9ce38c6424d27d2069896a9b8b1ce685d79936bca6e0a8", res)
+		assert.Equal(s.T(), map[string]interface{}{"major_version": float64(16), "height": float64(2000000), "block_blob": "0e0ed286da8006", "seed_hash": "ab14a0bcd2a0"}, s.params)
+	}
+}
+
+func (s *daemonClientTestSuite) TestAddAuxPow() {
+	s.results["add_aux_pow"] = &AddAuxPowResponse{
+		AuxPow:          []AuxPow{{Hash: "7b35762de164b20", ID: "3200b4ea97c3b2a"}},
+		MerkleRoot:      "7b35762de164b20",
+		MerkleTreeDepth: 0,
+		Status:          "OK",
+	}
+
+	res, err := NewDaemonClient(s.ts.URL, "username", "password").AddAuxPow("1010f4bae0b4069d", []AuxPow{{Hash: "7b35762de164b20", ID: "3200b4ea97c3b2a"}})
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), "OK", res.Status)
+		assert.Equal(s.T(), "7b35762de164b20", res.MerkleRoot)
+		assert.Equal(s.T(), []AuxPow{{Hash: "7b35762de164b20", ID: "3200b4ea97c3b2a"}}, res.AuxPow)
+		assert.Equal(s.T(), "1010f4bae0b4069d", s.params["blocktemplate_blob"])
+		assert.Equal(s.T(), []interface{}{map[string]interface{}{"hash": "7b35762de164b20", "id": "3200b4ea97c3b2a"}}, s.params["aux_pow"])
+	}
+}
+
+func (s *daemonClientTestSuite) TestFlushCache() {
+	res, err := NewDaemonClient(s.ts.URL, "username", "password").FlushCache(true, false)
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), "OK", res.Status)
+		assert.Equal(s.T(), map[string]interface{}{"bad_txs": true, "bad_blocks": false}, s.params)
+	}
+}
+
+func (s *daemonClientTestSuite) TestPruneBlockchain() {
+	s.results["prune_blockchain"] = &PruneBlockchainResponse{Pruned: true, PruningSeed: 387, Status: "OK"}
+
+	res, err := NewDaemonClient(s.ts.URL, "username", "password").PruneBlockchain(true)
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), "OK", res.Status)
+		assert.True(s.T(), res.Pruned)
+		assert.Equal(s.T(), uint(387), res.PruningSeed)
+		assert.Equal(s.T(), map[string]interface{}{"check": true}, s.params)
+	}
+}
+
+func (s *daemonClientTestSuite) TestGetTxidsLoose() {
+	s.results["get_txids_loose"] = &TxidsLooseResponse{Txids: []string{"2f3e9a1b"}, Status: "OK"}
+
+	res, err := NewDaemonClient(s.ts.URL, "username", "password").GetTxidsLoose("2f3e0000", 16)
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), "OK", res.Status)
+		assert.Equal(s.T(), []string{"2f3e9a1b"}, res.Txids)
+		assert.Equal(s.T(), map[string]interface{}{"txid_template": "2f3e0000", "num_matching_bits": float64(16)}, s.params)
+	}
+}
+
+func (s *daemonClientTestSuite) TestGenerateBlocks() {
+	s.results["generateblocks"] = &GenerateBlocksResponse{Blocks: []string{"49b712db7760e3"}, Height: 9783, Status: "OK"}
+
+	res, err := NewDaemonClient(s.ts.URL, "username", "password").GenerateBlocks(1, "44AFFq5kSiGBoZ...", "", 0)
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), "OK", res.Status)
+		assert.Equal(s.T(), []string{"49b712db7760e3"}, res.Blocks)
+		assert.Equal(s.T(), uint(9783), res.Height)
+		assert.Equal(s.T(), map[string]interface{}{"amount_of_blocks": float64(1), "wallet_address": "44AFFq5kSiGBoZ...", "starting_nonce": float64(0)}, s.params)
+	}
+}
+
+func (s *daemonClientTestSuite) TestBanned() {
+	s.results["banned"] = &BannedResponse{Banned: true, Seconds: 3600, Status: "OK", Untrusted: true}
+
+	res, err := NewDaemonClient(s.ts.URL, "username", "password").Banned("127.0.0.1")
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), BannedResponse{Banned: true, Seconds: 3600, Status: "OK", Untrusted: true}, res)
+		assert.Equal(s.T(), map[string]interface{}{"address": "127.0.0.1"}, s.params)
+	}
+}
+
+func (s *daemonClientTestSuite) TestRPCAccessInfo() {
+	s.results["rpc_access_info"] = &RPCAccessInfoResponse{Cookie: 3, Credits: 1500, Diff: 1000, HashingBlob: "0e0ed286da8006", Height: 2731375, Status: "OK"}
+
+	res, err := NewDaemonClient(s.ts.URL, "username", "password").RPCAccessInfo("a1b2c3")
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), "OK", res.Status)
+		assert.Equal(s.T(), uint(3), res.Cookie)
+		assert.Equal(s.T(), uint(1500), res.Credits)
+		assert.Equal(s.T(), uint(1000), res.Diff)
+		assert.Equal(s.T(), "0e0ed286da8006", res.HashingBlob)
+		assert.Equal(s.T(), map[string]interface{}{"client": "a1b2c3"}, s.params)
+	}
+}
+
+func (s *daemonClientTestSuite) TestRPCAccessSubmitNonce() {
+	s.results["rpc_access_submit_nonce"] = &RPCAccessResponse{Credits: 2500, Status: "OK", TopHash: "ab14a0bcd2a0"}
+
+	res, err := NewDaemonClient(s.ts.URL, "username", "password").RPCAccessSubmitNonce("a1b2c3", 1234, 3)
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), RPCAccessResponse{Credits: 2500, Status: "OK", TopHash: "ab14a0bcd2a0"}, res)
+		assert.Equal(s.T(), map[string]interface{}{"client": "a1b2c3", "nonce": float64(1234), "cookie": float64(3)}, s.params)
+	}
+}
+
+func (s *daemonClientTestSuite) TestRPCAccessPay() {
+	s.results["rpc_access_pay"] = &RPCAccessResponse{Credits: 900, Status: "OK"}
+
+	res, err := NewDaemonClient(s.ts.URL, "username", "password").RPCAccessPay("a1b2c3", "get_blocks", 100)
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), "OK", res.Status)
+		assert.Equal(s.T(), uint(900), res.Credits)
+		assert.Equal(s.T(), map[string]interface{}{"client": "a1b2c3", "paying_for": "get_blocks", "payment": float64(100)}, s.params)
+	}
+}
+
+func (s *daemonClientTestSuite) TestRPCAccessTracking() {
+	s.results["rpc_access_tracking"] = &RPCAccessTrackingResponse{Data: []RPCAccessTrackingEntry{{Count: 4, Credits: 400, RPC: "get_info", Time: 120}}, Status: "OK"}
+
+	res, err := NewDaemonClient(s.ts.URL, "username", "password").RPCAccessTracking(true)
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), "OK", res.Status)
+		assert.Equal(s.T(), []RPCAccessTrackingEntry{{Count: 4, Credits: 400, RPC: "get_info", Time: 120}}, res.Data)
+		assert.Equal(s.T(), map[string]interface{}{"clear": true}, s.params)
+	}
+}
+
+func (s *daemonClientTestSuite) TestRPCAccessData() {
+	s.results["rpc_access_data"] = &RPCAccessDataResponse{Entries: []RPCAccessDataEntry{{Balance: 100, Client: "a1b2c3", NoncesGood: 7}}, Hashrate: 350, Status: "OK"}
+
+	res, err := NewDaemonClient(s.ts.URL, "username", "password").RPCAccessData()
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), "OK", res.Status)
+		assert.Equal(s.T(), uint(350), res.Hashrate)
+		assert.Equal(s.T(), []RPCAccessDataEntry{{Balance: 100, Client: "a1b2c3", NoncesGood: 7}}, res.Entries)
+	}
+}
+
+func (s *daemonClientTestSuite) TestRPCAccessAccount() {
+	s.results["rpc_access_account"] = &RPCAccessAccountResponse{Credits: 50, Status: "OK"}
+
+	res, err := NewDaemonClient(s.ts.URL, "username", "password").RPCAccessAccount("a1b2c3", -50)
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), "OK", res.Status)
+		assert.Equal(s.T(), uint(50), res.Credits)
+		assert.Equal(s.T(), map[string]interface{}{"client": "a1b2c3", "delta_balance": float64(-50)}, s.params)
+	}
+}
+
 func (s *daemonClientTestSuite) TestGetHeight() {
 	res, err := NewDaemonClient(s.ts.URL, "username", "password").GetHeight()
 	if assert.NoError(s.T(), err) {
@@ -367,6 +591,7 @@ func (s *daemonClientTestSuite) TestInPeers() {
 	res, err := NewDaemonClient(s.ts.URL, "username", "password").InPeers(0)
 	if assert.NoError(s.T(), err) {
 		assert.Equal(s.T(), "OK", res.Status)
+		assert.Equal(s.T(), "/in_peers", s.uri)
 	}
 }
 
@@ -390,3 +615,56 @@ func (s *daemonClientTestSuite) TestUpdate() {
 		assert.Equal(s.T(), "OK", res.Status)
 	}
 }
+
+func (s *daemonClientTestSuite) TestSetBootstrapDaemon() {
+	res, err := NewDaemonClient(s.ts.URL, "username", "password").SetBootstrapDaemon("auto", "user", "pass", "127.0.0.1:9050")
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), "OK", res.Status)
+		assert.Equal(s.T(), "/set_bootstrap_daemon", s.uri)
+		assert.Equal(s.T(), map[string]interface{}{"address": "auto", "username": "user", "password": "pass", "proxy": "127.0.0.1:9050"}, s.params)
+	}
+}
+
+func (s *daemonClientTestSuite) TestGetPublicNodes() {
+	s.results["/get_public_nodes"] = &PublicNodesResponse{White: []PublicNode{{Host: "node.example.com", RPCPort: 18089}}, Status: "OK"}
+
+	res, err := NewDaemonClient(s.ts.URL, "username", "password").GetPublicNodes(false, true, false)
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), "OK", res.Status)
+		assert.Equal(s.T(), []PublicNode{{Host: "node.example.com", RPCPort: 18089}}, res.White)
+		assert.Empty(s.T(), res.Gray)
+		assert.Equal(s.T(), map[string]interface{}{"gray": false, "white": true, "include_blocked": false}, s.params)
+	}
+}
+
+func (s *daemonClientTestSuite) TestGetNetStats() {
+	s.results["/get_net_stats"] = &NetStatsResponse{StartTime: 1665944820, Status: "OK", TotalBytesIn: 7238656, TotalBytesOut: 1263872, TotalPacketsIn: 2451, TotalPacketsOut: 811}
+
+	res, err := NewDaemonClient(s.ts.URL, "username", "password").GetNetStats()
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), NetStatsResponse{StartTime: 1665944820, Status: "OK", TotalBytesIn: 7238656, TotalBytesOut: 1263872, TotalPacketsIn: 2451, TotalPacketsOut: 811}, res)
+		assert.Equal(s.T(), "/get_net_stats", s.uri)
+	}
+}
+
+func (s *daemonClientTestSuite) TestGetTransactionPoolHashes() {
+	s.results["/get_transaction_pool_hashes"] = &TransactionPoolHashesResponse{Status: "OK", TxHashes: []string{"9aa5e2d1a1f3", "2f3e9a1b"}}
+
+	res, err := NewDaemonClient(s.ts.URL, "username", "password").GetTransactionPoolHashes()
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), "OK", res.Status)
+		assert.Equal(s.T(), []string{"9aa5e2d1a1f3", "2f3e9a1b"}, res.TxHashes)
+		assert.Equal(s.T(), "/get_transaction_pool_hashes", s.uri)
+	}
+}
+
+func (s *daemonClientTestSuite) TestPopBlocks() {
+	s.results["/pop_blocks"] = &PopBlocksResponse{Height: 76482, Status: "OK"}
+
+	res, err := NewDaemonClient(s.ts.URL, "username", "password").PopBlocks(2)
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), "OK", res.Status)
+		assert.Equal(s.T(), uint(76482), res.Height)
+		assert.Equal(s.T(), map[string]interface{}{"nblocks": float64(2)}, s.params)
+	}
+}
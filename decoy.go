@@ -0,0 +1,172 @@
+package xmrrpc
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Parameters of the output age distribution used by the reference wallet.
+const (
+	decoyGammaShape        = 19.28
+	decoyGammaScale        = 1 / 1.61
+	decoyUnlockTime        = 1200
+	decoyRecentSpendWindow = 1800
+
+	decoySpendableAge    = 10
+	decoyBlockTime       = 120
+	decoyBlocksPerYear   = 86400 * 365 / decoyBlockTime
+	decoyAttemptsPerPick = 100
+)
+
+var ErrNotEnoughOutputs = errors.New("Not enough outputs to pick decoys from")
+
+// DecoyPicker selects decoy outputs by age like the reference wallet, from the
+// cumulative RingCT output distribution. It is not safe for concurrent use.
+type DecoyPicker struct {
+	offsets           []uint
+	outputs           uint
+	averageOutputTime float64
+	rand              *rand.Rand
+}
+
+// NewDecoyPicker returns a picker for offsets, the cumulative number of RingCT
+// outputs per block as returned by GetOutputDistribution.
+func NewDecoyPicker(offsets []uint) (*DecoyPicker, error) {
+	if len(offsets) <= decoySpendableAge {
+		return nil, ErrNotEnoughOutputs
+	}
+
+	blocks := len(offsets)
+	if blocks > decoyBlocksPerYear {
+		blocks = decoyBlocksPerYear
+	}
+
+	outputs := offsets[len(offsets)-1]
+	if blocks < len(offsets) {
+		outputs -= offsets[len(offsets)-blocks-1]
+	}
+	if outputs == 0 {
+		return nil, ErrNotEnoughOutputs
+	}
+
+	return &DecoyPicker{
+		offsets:           offsets[:len(offsets)-decoySpendableAge],
+		outputs:           offsets[len(offsets)-decoySpendableAge-1],
+		averageOutputTime: float64(decoyBlockTime*blocks) / float64(outputs),
+		rand:              rand.New(cryptoSource{}),
+	}, nil
+}
+
+func (dc *DaemonClient) DecoyPicker() (*DecoyPicker, error) {
+	return dc.DecoyPickerContext(context.Background())
+}
+
+func (dc *DaemonClient) DecoyPickerContext(ctx context.Context) (*DecoyPicker, error) {
+	res, err := dc.GetOutputDistributionContext(ctx, []uint{0}, true, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res.Distributions) == 0 {
+		return nil, ErrNotEnoughOutputs
+	}
+
+	return NewDecoyPicker(res.Distributions[0].Distribution)
+}
+
+// Pick returns n distinct global output indexes in ascending order, skipping
+// the exclude indexes (e.g. the real output of a ring).
+func (p *DecoyPicker) Pick(n int, exclude ...uint) ([]uint, error) {
+	picked := make(map[uint]bool, n+len(exclude))
+	for _, i := range exclude {
+		picked[i] = true
+	}
+
+	result := make([]uint, 0, n)
+	for attempts := 0; len(result) < n; attempts++ {
+		if attempts >= n*decoyAttemptsPerPick {
+			return nil, ErrNotEnoughOutputs
+		}
+
+		i, ok := p.pick()
+		if !ok || picked[i] {
+			continue
+		}
+
+		picked[i] = true
+		result = append(result, i)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result, nil
+}
+
+func (p *DecoyPicker) pick() (uint, bool) {
+	x := math.Exp(p.gamma(decoyGammaShape, decoyGammaScale))
+	if x > decoyUnlockTime {
+		x -= decoyUnlockTime
+	} else {
+		x = float64(p.rand.Intn(decoyRecentSpendWindow))
+	}
+
+	age := uint(x / p.averageOutputTime)
+	if age >= p.outputs {
+		return 0, false
+	}
+
+	index := p.outputs - 1 - age
+	block := sort.Search(len(p.offsets), func(i int) bool { return p.offsets[i] > index })
+
+	var first uint
+	if block > 0 {
+		first = p.offsets[block-1]
+	}
+	if p.offsets[block] == first {
+		return 0, false
+	}
+
+	return first + uint(p.rand.Int63n(int64(p.offsets[block]-first))), true
+}
+
+// cryptoSource draws every random number from crypto/rand, as the reference
+// wallet does with crypto::rand_idx.
+type cryptoSource struct{}
+
+func (cryptoSource) Int63() int64 {
+	return int64(cryptoSource{}.Uint64() >> 1)
+}
+
+func (cryptoSource) Uint64() uint64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		panic(err)
+	}
+
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+func (cryptoSource) Seed(int64) {}
+
+// gamma samples the gamma distribution with the Marsaglia and Tsang method,
+// shape must be at least 1.
+func (p *DecoyPicker) gamma(shape float64, scale float64) float64 {
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := p.rand.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+
+		v = v * v * v
+		if math.Log(p.rand.Float64()) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v * scale
+		}
+	}
+}
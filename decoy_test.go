@@ -0,0 +1,146 @@
+package xmrrpc
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type decoyTestSuite struct {
+	suite.Suite
+	offsets []uint
+}
+
+func (s *decoyTestSuite) SetupTest() {
+	// 10 outputs per block over 2000 blocks.
+	s.offsets = make([]uint, 2000)
+	for i := range s.offsets {
+		s.offsets[i] = uint(i+1) * 10
+	}
+}
+
+func TestDecoyTestSuite(t *testing.T) {
+	suite.Run(t, new(decoyTestSuite))
+}
+
+func (s *decoyTestSuite) picker() *DecoyPicker {
+	p, err := NewDecoyPicker(s.offsets)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	p.rand = rand.New(rand.NewSource(1))
+
+	return p
+}
+
+func (s *decoyTestSuite) TestPick() {
+	p := s.picker()
+
+	decoys, err := p.Pick(15, 19985)
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), decoys, 15)
+	assert.NotContains(s.T(), decoys, uint(19985))
+	for i, d := range decoys {
+		// Outputs of the last 10 blocks are not spendable yet.
+		assert.Less(s.T(), d, uint(19900))
+		if i > 0 {
+			assert.Less(s.T(), decoys[i-1], d)
+		}
+	}
+}
+
+func (s *decoyTestSuite) TestPickRecent() {
+	p := s.picker()
+
+	// The gamma distribution favours recent outputs, with a median age of
+	// about 1.8 days, i.e. output 19900 - 1.8 * 720 * 10.
+	var recent int
+	for i := 0; i < 1000; i++ {
+		index, ok := p.pick()
+		if ok && index >= 19900-uint(1.8*720*10) {
+			recent++
+		}
+	}
+	assert.InDelta(s.T(), 500, recent, 100)
+}
+
+func (s *decoyTestSuite) TestPickNotEnough() {
+	s.offsets = []uint{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+	p := s.picker()
+
+	_, err := p.Pick(3)
+	assert.ErrorIs(s.T(), err, ErrNotEnoughOutputs)
+}
+
+func (s *decoyTestSuite) TestNewDecoyPicker() {
+	_, err := NewDecoyPicker(make([]uint, 10))
+	assert.ErrorIs(s.T(), err, ErrNotEnoughOutputs)
+
+	_, err = NewDecoyPicker(make([]uint, 20))
+	assert.ErrorIs(s.T(), err, ErrNotEnoughOutputs)
+}
+
+func (s *decoyTestSuite) TestAverageOutputTime() {
+	// One output per block, then 10 per block over the last day: the average
+	// is taken over a year of blocks like the reference wallet.
+	s.offsets = make([]uint, 300000)
+	var total uint
+	for i := range s.offsets {
+		total++
+		if i >= len(s.offsets)-720 {
+			total += 9
+		}
+		s.offsets[i] = total
+	}
+
+	p, err := NewDecoyPicker(s.offsets)
+	assert.NoError(s.T(), err)
+	assert.InDelta(s.T(), 120*262800/float64(262080+7200), p.averageOutputTime, 1e-9)
+	assert.Equal(s.T(), s.offsets[len(s.offsets)-11], p.outputs)
+}
+
+func (s *decoyTestSuite) TestDecoyPickerContext() {
+	var params map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     uint64                 `json:"id"`
+			Method string                 `json:"method"`
+			Params map[string]interface{} `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		params = req.Params
+
+		// Like monerod, distributions are epee blobs unless binary is false.
+		binary, ok := req.Params["binary"].(bool)
+		binary = binary || !ok
+		var distribution interface{} = s.offsets
+		if binary {
+			distribution = "0100000000000000"
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":      req.ID,
+			"jsonrpc": "2.0",
+			"result": map[string]interface{}{
+				"distributions": []map[string]interface{}{{"amount": 0, "binary": binary, "distribution": distribution}},
+				"status":        "OK",
+			},
+		})
+	}))
+	defer ts.Close()
+
+	p, err := NewDaemonClient(ts.URL, "", "").DecoyPicker()
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []interface{}{float64(0)}, params["amounts"])
+	assert.Equal(s.T(), true, params["cumulative"])
+	assert.Equal(s.T(), false, params["binary"])
+
+	decoys, err := p.Pick(10)
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), decoys, 10)
+}
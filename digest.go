@@ -0,0 +1,248 @@
+package xmrrpc
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+type digestAlgorithm struct {
+	name string
+	sess bool
+	h    func(s string) string
+}
+
+// Ordered from the strongest to the weakest.
+var digestAlgorithms = []digestAlgorithm{
+	{name: "SHA-512-256-SESS", sess: true, h: hSHA512256},
+	{name: "SHA-512-256", h: hSHA512256},
+	{name: "SHA-256-SESS", sess: true, h: hSHA256},
+	{name: "SHA-256", h: hSHA256},
+	{name: "MD5-SESS", sess: true, h: h},
+	{name: "MD5", h: h},
+}
+
+type digestAuth struct {
+	mu        sync.Mutex
+	username  string
+	password  string
+	params    map[string]string
+	algorithm *digestAlgorithm
+	cnonce    string
+	nc        uint32
+
+	onChallenge func(ctx context.Context) (context.Context, func(err error))
+}
+
+func findDigestAlgorithm(name string) (int, *digestAlgorithm) {
+	if name == "" {
+		name = "MD5"
+	}
+
+	for i := range digestAlgorithms {
+		if strings.EqualFold(digestAlgorithms[i].name, name) {
+			return i, &digestAlgorithms[i]
+		}
+	}
+
+	return len(digestAlgorithms), nil
+}
+
+func parseChallenges(header string) []map[string]string {
+	var challenges []map[string]string
+	var current map[string]string
+
+	for i := 0; i < len(header); {
+		for i < len(header) && (header[i] == ' ' || header[i] == '\t' || header[i] == ',') {
+			i++
+		}
+
+		start := i
+		for i < len(header) && header[i] != ' ' && header[i] != '\t' && header[i] != ',' && header[i] != '=' {
+			i++
+		}
+		token := header[start:i]
+		if token == "" {
+			i++
+			continue
+		}
+
+		j := i
+		for j < len(header) && (header[j] == ' ' || header[j] == '\t') {
+			j++
+		}
+
+		if j >= len(header) || header[j] != '=' {
+			current = map[string]string{"": token}
+			challenges = append(challenges, current)
+			continue
+		}
+
+		i = j + 1
+		for i < len(header) && (header[i] == ' ' || header[i] == '\t') {
+			i++
+		}
+
+		var value strings.Builder
+		if i < len(header) && header[i] == '"' {
+			for i++; i < len(header) && header[i] != '"'; i++ {
+				if header[i] == '\\' && i+1 < len(header) {
+					i++
+				}
+				value.WriteByte(header[i])
+			}
+			i++
+		} else {
+			for ; i < len(header) && header[i] != ',' && header[i] != ' ' && header[i] != '\t'; i++ {
+				value.WriteByte(header[i])
+			}
+		}
+
+		if current != nil {
+			current[strings.ToLower(token)] = value.String()
+		}
+	}
+
+	return challenges
+}
+
+func digestAuthParams(response *http.Response) map[string]string {
+	var result map[string]string
+	var rank = len(digestAlgorithms)
+
+	for _, header := range response.Header["Www-Authenticate"] {
+		for _, challenge := range parseChallenges(header) {
+			if !strings.EqualFold(challenge[""], "Digest") {
+				continue
+			}
+
+			if i, _ := findDigestAlgorithm(challenge["algorithm"]); i < rank {
+				delete(challenge, "")
+				result, rank = challenge, i
+			}
+		}
+	}
+
+	return result
+}
+
+func randomKey() string {
+	k := make([]byte, 8)
+	if _, err := rand.Read(k); err != nil {
+		panic(err)
+	}
+
+	return base64.StdEncoding.EncodeToString(k)
+}
+
+func hashHex(digest hash.Hash, s string) string {
+	if _, err := digest.Write([]byte(s)); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(digest.Sum(nil))
+}
+
+func h(s string) string {
+	return hashHex(md5.New(), s)
+}
+
+func hSHA256(s string) string {
+	return hashHex(sha256.New(), s)
+}
+
+func hSHA512256(s string) string {
+	return hashHex(sha512.New512_256(), s)
+}
+
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func newDigestAuth(username string, password string) *digestAuth {
+	return &digestAuth{username: username, password: password}
+}
+
+func (da *digestAuth) authorization(method string, uri string, body []byte) string {
+	da.mu.Lock()
+	defer da.mu.Unlock()
+
+	if da.params == nil {
+		return ""
+	}
+
+	da.nc++
+
+	var realm = da.params["realm"]
+	var nonce = da.params["nonce"]
+	var nc = fmt.Sprintf("%08x", da.nc)
+	var qop string
+	for _, q := range strings.Split(da.params["qop"], ",") {
+		q = strings.TrimSpace(q)
+		if q == "auth" || (q == "auth-int" && qop == "") {
+			qop = q
+		}
+	}
+
+	ha1 := da.algorithm.h(da.username + ":" + realm + ":" + da.password)
+	if da.algorithm.sess {
+		ha1 = da.algorithm.h(ha1 + ":" + nonce + ":" + da.cnonce)
+	}
+
+	ha2 := da.algorithm.h(method + ":" + uri)
+	if qop == "auth-int" {
+		ha2 = da.algorithm.h(method + ":" + uri + ":" + da.algorithm.h(string(body)))
+	}
+
+	var response string
+	if qop == "" {
+		response = da.algorithm.h(ha1 + ":" + nonce + ":" + ha2)
+	} else {
+		response = da.algorithm.h(strings.Join([]string{ha1, nonce, nc, da.cnonce, qop, ha2}, ":"))
+	}
+
+	params := []string{
+		"username=" + quote(da.username),
+		"realm=" + quote(realm),
+		"nonce=" + quote(nonce),
+		"uri=" + quote(uri),
+		"response=" + quote(response),
+	}
+	if algorithm, ok := da.params["algorithm"]; ok {
+		params = append(params, "algorithm="+algorithm)
+	}
+	if opaque, ok := da.params["opaque"]; ok {
+		params = append(params, "opaque="+quote(opaque))
+	}
+	if qop != "" {
+		params = append(params, "qop="+qop, "nc="+nc, "cnonce="+quote(da.cnonce))
+	}
+
+	return "Digest " + strings.Join(params, ", ")
+}
+
+func (da *digestAuth) challenge(response *http.Response) bool {
+	params := digestAuthParams(response)
+	if params == nil {
+		return false
+	}
+
+	da.mu.Lock()
+	defer da.mu.Unlock()
+
+	_, da.algorithm = findDigestAlgorithm(params["algorithm"])
+	da.params = params
+	da.cnonce = randomKey()
+	da.nc = 0
+
+	return true
+}
@@ -0,0 +1,193 @@
+package xmrrpc
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type digestTestSuite struct {
+	suite.Suite
+}
+
+func TestDigestTestSuite(t *testing.T) {
+	suite.Run(t, new(digestTestSuite))
+}
+
+func (s *digestTestSuite) TestDigestAuthParams() {
+	res1 := &httptest.ResponseRecorder{}
+	res1.Header().Add("WWW-authenticate", `NotDigest qop="auth",algorithm=MD5`)
+	if assert.Empty(s.T(), digestAuthParams(res1.Result())) {
+		res2 := &httptest.ResponseRecorder{}
+		res2.Header().Add("WWW-authenticate", `Digest qop="auth",algorithm=MD5,realm="monero-rpc",nonce,stale=false`)
+		d := digestAuthParams(res2.Result())
+		if assert.Empty(s.T(), d["nonce"]) {
+			res3 := &httptest.ResponseRecorder{}
+			res3.Header().Add("WWW-authenticate", `Digest qop="auth",algorithm=MD5,realm="monero-rpc",nonce="jmk3hzH2xpPmUBSD0uy+uQ==",stale=false`)
+			d = digestAuthParams(res3.Result())
+			if assert.NotEmpty(s.T(), d) {
+				assert.Equal(s.T(), "jmk3hzH2xpPmUBSD0uy+uQ==", d["nonce"])
+			}
+		}
+	}
+}
+
+func (s *digestTestSuite) TestRandomKey() {
+	k := randomKey()
+	if assert.NotPanics(s.T(), func() { randomKey() }) {
+		if assert.Len(s.T(), k, 12, "Key length is incorrect.") {
+			d, err := base64.StdEncoding.DecodeString(k)
+			if assert.NoError(s.T(), err, "Key encode is incorrect.") {
+				assert.Len(s.T(), d, 8, "Key bytes length is incorrect.")
+			}
+		}
+	}
+}
+
+func (s *digestTestSuite) TestH() {
+	if assert.NotPanics(s.T(), func() { h("1234567890") }) {
+		assert.Equal(s.T(), "e807f1fcf82d132f9bb018ca6738a19f", h("1234567890"), "MD5 hash is incorrect.")
+	}
+}
+
+func (s *digestTestSuite) TestParseChallenges() {
+	c := parseChallenges(`Basic realm="monero, rpc", Digest realm="a \"quoted\", realm", nonce="abc,def", algorithm=SHA-256, qop="auth,auth-int"`)
+	if assert.Len(s.T(), c, 2) {
+		assert.Equal(s.T(), "Basic", c[0][""])
+		assert.Equal(s.T(), "monero, rpc", c[0]["realm"])
+		assert.Equal(s.T(), "Digest", c[1][""])
+		assert.Equal(s.T(), `a "quoted", realm`, c[1]["realm"])
+		assert.Equal(s.T(), "abc,def", c[1]["nonce"])
+		assert.Equal(s.T(), "SHA-256", c[1]["algorithm"])
+		assert.Equal(s.T(), "auth,auth-int", c[1]["qop"])
+	}
+}
+
+func (s *digestTestSuite) TestDigestAuthParamsStrongest() {
+	res := &httptest.ResponseRecorder{}
+	res.Header().Add("WWW-authenticate", `Digest qop="auth",algorithm=MD5,realm="monero-rpc",nonce="n1"`)
+	res.Header().Add("WWW-authenticate", `Digest qop="auth",algorithm=UNKNOWN,realm="monero-rpc",nonce="n2"`)
+	res.Header().Add("WWW-authenticate", `Digest qop="auth",algorithm=MD5-sess,realm="monero-rpc",nonce="n3", Digest qop="auth",algorithm=SHA-256,realm="monero-rpc",nonce="n4"`)
+	d := digestAuthParams(res.Result())
+	if assert.NotEmpty(s.T(), d) {
+		assert.Equal(s.T(), "SHA-256", d["algorithm"])
+		assert.Equal(s.T(), "n4", d["nonce"])
+	}
+
+	res = &httptest.ResponseRecorder{}
+	res.Header().Add("WWW-authenticate", `Digest qop="auth",algorithm=SHA-512-256,realm="monero-rpc",nonce="n1"`)
+	res.Header().Add("WWW-authenticate", `Digest qop="auth",realm="monero-rpc",nonce="n2"`)
+	d = digestAuthParams(res.Result())
+	if assert.NotEmpty(s.T(), d) {
+		assert.Equal(s.T(), "n1", d["nonce"])
+	}
+}
+
+// Examples from RFC 7616, section 3.9.1.
+func (s *digestTestSuite) TestAuthorizationRFC7616() {
+	for algorithm, expected := range map[string]string{
+		"MD5":     "8ca523f5e9506fed4657c9700eebdbec",
+		"SHA-256": "753927fa0e85d155564e2e272a28d1802ca10daf4496794697cf8db5856cb6c1",
+	} {
+		res := &httptest.ResponseRecorder{}
+		res.Header().Add("WWW-Authenticate", fmt.Sprintf(`Digest realm="http-auth@example.org", qop="auth, auth-int", algorithm=%s, nonce="7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v", opaque="FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS"`, algorithm))
+
+		da := newDigestAuth("Mufasa", "Circle of Life")
+		if assert.True(s.T(), da.challenge(res.Result())) {
+			da.cnonce = "f2/wE4q74E6zIJEtWaHKaf5wv/H5QzzpXusqGemxURZJ"
+
+			a := da.authorization(http.MethodGet, "/dir/index.html", nil)
+			assert.Contains(s.T(), a, fmt.Sprintf(`response="%s"`, expected), algorithm)
+			assert.Contains(s.T(), a, `opaque="FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS"`)
+			assert.Contains(s.T(), a, `uri="/dir/index.html"`)
+			assert.Contains(s.T(), a, `qop=auth, nc=00000001, cnonce="f2/wE4q74E6zIJEtWaHKaf5wv/H5QzzpXusqGemxURZJ"`)
+			assert.Contains(s.T(), a, "algorithm="+algorithm)
+		}
+	}
+}
+
+func digestVerify(r *http.Request, password string, body []byte) bool {
+	params := authorizationParams(r)
+	if params == nil {
+		return false
+	}
+
+	var newHash func() hash.Hash
+	switch strings.ToUpper(strings.TrimSuffix(strings.ToLower(params["algorithm"]), "-sess")) {
+	case "", "MD5":
+		newHash = md5.New
+	case "SHA-256":
+		newHash = sha256.New
+	case "SHA-512-256":
+		newHash = sha512.New512_256
+	default:
+		return false
+	}
+
+	hx := func(s string) string {
+		d := newHash()
+		d.Write([]byte(s))
+		return hex.EncodeToString(d.Sum(nil))
+	}
+
+	ha1 := hx(params["username"] + ":" + params["realm"] + ":" + password)
+	if strings.HasSuffix(strings.ToLower(params["algorithm"]), "-sess") {
+		ha1 = hx(ha1 + ":" + params["nonce"] + ":" + params["cnonce"])
+	}
+
+	ha2 := hx(r.Method + ":" + params["uri"])
+	if params["qop"] == "auth-int" {
+		ha2 = hx(r.Method + ":" + params["uri"] + ":" + hx(string(body)))
+	}
+
+	expected := hx(ha1 + ":" + params["nonce"] + ":" + ha2)
+	if params["qop"] != "" {
+		expected = hx(ha1 + ":" + params["nonce"] + ":" + params["nc"] + ":" + params["cnonce"] + ":" + params["qop"] + ":" + ha2)
+	}
+
+	return params["uri"] == r.URL.RequestURI() && params["response"] == expected
+}
+
+func (s *digestTestSuite) TestRequestAlgorithms() {
+	for _, challenge := range []string{
+		`Digest qop="auth",algorithm=MD5,realm="monero-rpc",nonce="jmk3hzH2xpPmUBSD0uy+uQ==",stale=false`,
+		`Digest qop="auth",algorithm=MD5-sess,realm="monero-rpc",nonce="jmk3hzH2xpPmUBSD0uy+uQ==",stale=false`,
+		`Digest qop="auth",algorithm=SHA-256,realm="monero-rpc",nonce="jmk3hzH2xpPmUBSD0uy+uQ==",opaque="op,aque"`,
+		`Digest qop="auth-int",algorithm=SHA-256-sess,realm="monero-rpc",nonce="jmk3hzH2xpPmUBSD0uy+uQ=="`,
+		`Digest qop="auth",algorithm=SHA-512-256,realm="monero, rpc",nonce="jmk3hzH2xpPmUBSD0uy+uQ=="`,
+		`Digest realm="monero-rpc",nonce="jmk3hzH2xpPmUBSD0uy+uQ=="`,
+	} {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			params := authorizationParams(r)
+
+			if !digestVerify(r, "password", body) || (strings.Contains(challenge, "opaque") && params["opaque"] != "op,aque") {
+				w.Header().Add("WWW-authenticate", challenge)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			w.Write([]byte(`{"status":"OK"}`))
+		}))
+
+		res, err := request(context.Background(), http.DefaultClient, http.MethodPost, ts.URL+"/get_height", nil, []byte(`{}`), newDigestAuth("username", "password"))
+		if assert.NoError(s.T(), err) {
+			assert.Equal(s.T(), http.StatusOK, res.StatusCode, challenge)
+		}
+
+		ts.Close()
+	}
+}
@@ -0,0 +1,504 @@
+package epee
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Unmarshal decodes portable storage data into v, a pointer to a struct, a map
+// with string keys or an interface{}. Unknown entries are skipped, integers are
+// converted between sizes when the value fits.
+//
+// Into an interface{} sections are decoded as map[string]interface{}, arrays as
+// []interface{}, strings as []byte and numbers as the Go type of the same size.
+func Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("Unmarshal target must be a non-nil pointer: %T", v)
+	}
+
+	if len(data) < len(header) {
+		return ErrTruncated
+	}
+
+	for i := range header {
+		if data[i] != header[i] {
+			return ErrInvalidHeader
+		}
+	}
+
+	d := &decoder{data: data, pos: len(header)}
+	if err := d.section(rv.Elem()); err != nil {
+		return err
+	}
+
+	if d.pos != len(d.data) {
+		return fmt.Errorf("Unexpected %d bytes after portable storage data", len(d.data)-d.pos)
+	}
+
+	return nil
+}
+
+type decoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func (d *decoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, ErrTruncated
+	}
+
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+
+	return b, nil
+}
+
+func (d *decoder) byte() (byte, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return 0, err
+	}
+
+	return b[0], nil
+}
+
+func (d *decoder) varint() (uint64, error) {
+	v, n, err := readVarint(d.data[d.pos:])
+	d.pos += n
+
+	return v, err
+}
+
+// count reads an item count and checks it against the remaining data, every item takes at least min bytes.
+func (d *decoder) count(min int) (int, error) {
+	n, err := d.varint()
+	if err != nil {
+		return 0, err
+	}
+
+	if min > 0 && n > uint64(len(d.data)-d.pos)/uint64(min) {
+		return 0, ErrTruncated
+	}
+
+	return int(n), nil
+}
+
+func (d *decoder) section(v reflect.Value) error {
+	if d.depth++; d.depth > maxDepth {
+		return ErrTooDeep
+	}
+	defer func() { d.depth-- }()
+
+	v = allocate(v)
+
+	var byName map[string]field
+	switch {
+	case v.Kind() == reflect.Struct:
+		byName = map[string]field{}
+		for _, f := range fields(v.Type()) {
+			byName[f.name] = f
+		}
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+	case v.Kind() == reflect.Interface && v.NumMethod() == 0:
+		m := reflect.ValueOf(map[string]interface{}{})
+		defer v.Set(m)
+		v = m
+	default:
+		v = reflect.Value{}
+	}
+
+	n, err := d.count(3)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < n; i++ {
+		size, err := d.byte()
+		if err != nil {
+			return err
+		}
+
+		name, err := d.read(uint64(size))
+		if err != nil {
+			return err
+		}
+
+		typ, err := d.byte()
+		if err != nil {
+			return err
+		}
+
+		var target reflect.Value
+		var blob bool
+		switch {
+		case !v.IsValid():
+		case v.Kind() == reflect.Struct:
+			if f, ok := byName[string(name)]; ok {
+				target, blob = fieldAlloc(v, f.index), f.blob
+			}
+		default:
+			target = reflect.New(v.Type().Elem()).Elem()
+		}
+
+		if err := d.entry(typ, target, blob); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		if target.IsValid() && v.Kind() == reflect.Map {
+			v.SetMapIndex(reflect.ValueOf(string(name)).Convert(v.Type().Key()), target)
+		}
+	}
+
+	return nil
+}
+
+func (d *decoder) entry(typ byte, v reflect.Value, blob bool) error {
+	v = allocate(v)
+	if isUnmarshaler(v) {
+		return d.unmarshal(typ, v, blob)
+	}
+
+	if typ&flagArray != 0 {
+		return d.array(typ&^flagArray, v)
+	}
+
+	return d.value(typ, v, blob)
+}
+
+func (d *decoder) array(typ byte, v reflect.Value) error {
+	if d.depth++; d.depth > maxDepth {
+		return ErrTooDeep
+	}
+	defer func() { d.depth-- }()
+
+	min, ok := typeSize(typ)
+	if !ok {
+		return fmt.Errorf("Unknown portable storage type: %d", typ)
+	}
+
+	n, err := d.count(min)
+	if err != nil {
+		return err
+	}
+
+	v = allocate(v)
+
+	var items reflect.Value
+	switch {
+	case !v.IsValid():
+	case v.Kind() == reflect.Slice && !isBytes(v.Type()):
+		items = reflect.MakeSlice(v.Type(), n, n)
+	case v.Kind() == reflect.Array && !isBytes(v.Type()):
+		if n > v.Len() {
+			return fmt.Errorf("Array of %d items does not fit into %s", n, v.Type())
+		}
+		items = v
+	case v.Kind() == reflect.Interface && v.NumMethod() == 0:
+		items = reflect.ValueOf(make([]interface{}, n))
+	default:
+		return fmt.Errorf("Cannot decode array into %s", v.Type())
+	}
+
+	for i := 0; i < n; i++ {
+		var item reflect.Value
+		if items.IsValid() {
+			item = items.Index(i)
+		}
+
+		if err := d.value(typ, item, false); err != nil {
+			return err
+		}
+	}
+
+	if items.IsValid() && v.Kind() != reflect.Array {
+		v.Set(items)
+	}
+
+	return nil
+}
+
+func (d *decoder) value(typ byte, v reflect.Value, blob bool) error {
+	v = allocate(v)
+	if isUnmarshaler(v) {
+		return d.unmarshal(typ, v, blob)
+	}
+
+	switch typ {
+	case typeInt64, typeInt32, typeInt16, typeInt8:
+		size, _ := typeSize(typ)
+		b, err := d.read(uint64(size))
+		if err != nil {
+			return err
+		}
+		return setInt(v, typ, signExtend(b))
+	case typeUint64, typeUint32, typeUint16, typeUint8:
+		size, _ := typeSize(typ)
+		b, err := d.read(uint64(size))
+		if err != nil {
+			return err
+		}
+		return setUint(v, typ, littleEndian(b))
+	case typeDouble:
+		b, err := d.read(8)
+		if err != nil {
+			return err
+		}
+		return setFloat(v, math.Float64frombits(binary.LittleEndian.Uint64(b)))
+	case typeBool:
+		b, err := d.byte()
+		if err != nil {
+			return err
+		}
+		return set(v, reflect.Bool, reflect.ValueOf(b != 0))
+	case typeString:
+		n, err := d.varint()
+		if err != nil {
+			return err
+		}
+		b, err := d.read(n)
+		if err != nil {
+			return err
+		}
+		return setString(v, b, blob)
+	case typeObject:
+		return d.section(v)
+	case typeArray:
+		t, err := d.byte()
+		if err != nil {
+			return err
+		}
+		if t&flagArray == 0 {
+			return fmt.Errorf("Unexpected portable storage type in array entry: %d", t)
+		}
+		return d.array(t&^flagArray, v)
+	}
+
+	return fmt.Errorf("Unknown portable storage type: %d", typ)
+}
+
+func (d *decoder) unmarshal(typ byte, v reflect.Value, blob bool) error {
+	var x interface{}
+	if err := d.entry(typ, reflect.ValueOf(&x).Elem(), blob); err != nil {
+		return err
+	}
+
+	return v.Addr().Interface().(Unmarshaler).UnmarshalEpee(x)
+}
+
+func isUnmarshaler(v reflect.Value) bool {
+	return v.IsValid() && v.Kind() != reflect.Interface && v.CanAddr() && v.Addr().Type().Implements(unmarshalerType)
+}
+
+// typeSize returns the minimal encoded size of a value of typ.
+func typeSize(typ byte) (int, bool) {
+	switch typ {
+	case typeInt64, typeUint64, typeDouble:
+		return 8, true
+	case typeInt32, typeUint32:
+		return 4, true
+	case typeInt16, typeUint16:
+		return 2, true
+	case typeInt8, typeUint8, typeBool, typeString, typeObject, typeArray:
+		return 1, true
+	}
+
+	return 0, false
+}
+
+func littleEndian(b []byte) uint64 {
+	var v uint64
+	for i := len(b) - 1; i >= 0; i-- {
+		v = v<<8 | uint64(b[i])
+	}
+
+	return v
+}
+
+func signExtend(b []byte) int64 {
+	shift := 64 - 8*uint(len(b))
+	return int64(littleEndian(b)<<shift) >> shift
+}
+
+func allocate(v reflect.Value) reflect.Value {
+	for v.IsValid() && v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	return v
+}
+
+func fieldAlloc(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 {
+			v = allocate(v)
+		}
+		v = v.Field(x)
+	}
+
+	return v
+}
+
+// interfaceValue returns the value of an epee scalar stored into an interface{}.
+func interfaceValue(typ byte, x interface{}) reflect.Value {
+	switch typ {
+	case typeInt64:
+		return reflect.ValueOf(x.(int64))
+	case typeInt32:
+		return reflect.ValueOf(int32(x.(int64)))
+	case typeInt16:
+		return reflect.ValueOf(int16(x.(int64)))
+	case typeInt8:
+		return reflect.ValueOf(int8(x.(int64)))
+	case typeUint64:
+		return reflect.ValueOf(x.(uint64))
+	case typeUint32:
+		return reflect.ValueOf(uint32(x.(uint64)))
+	case typeUint16:
+		return reflect.ValueOf(uint16(x.(uint64)))
+	case typeUint8:
+		return reflect.ValueOf(uint8(x.(uint64)))
+	}
+
+	return reflect.ValueOf(x)
+}
+
+func set(v reflect.Value, kind reflect.Kind, x reflect.Value) error {
+	switch {
+	case !v.IsValid():
+		return nil
+	case v.Kind() == kind:
+		v.Set(x.Convert(v.Type()))
+		return nil
+	case v.Kind() == reflect.Interface && v.NumMethod() == 0:
+		v.Set(x)
+		return nil
+	}
+
+	return fmt.Errorf("Cannot decode %s into %s", x.Type(), v.Type())
+}
+
+func setInt(v reflect.Value, typ byte, x int64) error {
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8:
+		if v.OverflowInt(x) {
+			return fmt.Errorf("Value %d overflows %s", x, v.Type())
+		}
+		v.SetInt(x)
+	case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+		if x < 0 || v.OverflowUint(uint64(x)) {
+			return fmt.Errorf("Value %d overflows %s", x, v.Type())
+		}
+		v.SetUint(uint64(x))
+	default:
+		return set(v, reflect.Int64, interfaceValue(typ, x))
+	}
+
+	return nil
+}
+
+func setUint(v reflect.Value, typ byte, x uint64) error {
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8:
+		if x > math.MaxInt64 || v.OverflowInt(int64(x)) {
+			return fmt.Errorf("Value %d overflows %s", x, v.Type())
+		}
+		v.SetInt(int64(x))
+	case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+		if v.OverflowUint(x) {
+			return fmt.Errorf("Value %d overflows %s", x, v.Type())
+		}
+		v.SetUint(x)
+	default:
+		return set(v, reflect.Uint64, interfaceValue(typ, x))
+	}
+
+	return nil
+}
+
+func setFloat(v reflect.Value, x float64) error {
+	if v.IsValid() && (v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64) {
+		v.SetFloat(x)
+		return nil
+	}
+
+	return set(v, reflect.Float64, reflect.ValueOf(x))
+}
+
+func setString(v reflect.Value, b []byte, blob bool) error {
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch {
+	case v.Kind() == reflect.String:
+		v.SetString(string(b))
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		v.SetBytes(append([]byte{}, b...))
+	case v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8:
+		if len(b) != v.Len() {
+			return fmt.Errorf("String of %d bytes does not fit into %s", len(b), v.Type())
+		}
+		reflect.Copy(v, reflect.ValueOf(b))
+	case blob && v.Kind() == reflect.Slice:
+		return unpackBlob(v, b)
+	case v.Kind() == reflect.Interface && v.NumMethod() == 0:
+		v.Set(reflect.ValueOf(append([]byte{}, b...)))
+	default:
+		return fmt.Errorf("Cannot decode string into %s", v.Type())
+	}
+
+	return nil
+}
+
+func unpackBlob(v reflect.Value, b []byte) error {
+	elem := v.Type().Elem()
+
+	var size int
+	switch elem.Kind() {
+	case reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		size = int(elem.Size())
+	case reflect.Array:
+		if elem.Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("Unsupported blob item: %s", elem)
+		}
+		size = elem.Len()
+	default:
+		return fmt.Errorf("Unsupported blob item: %s", elem)
+	}
+
+	if size == 0 || len(b)%size != 0 {
+		return fmt.Errorf("Blob of %d bytes is not a multiple of %s", len(b), elem)
+	}
+
+	items := reflect.MakeSlice(v.Type(), len(b)/size, len(b)/size)
+	for i := 0; i < items.Len(); i++ {
+		chunk := b[i*size : (i+1)*size]
+		if elem.Kind() == reflect.Array {
+			reflect.Copy(items.Index(i), reflect.ValueOf(chunk))
+		} else {
+			items.Index(i).SetUint(littleEndian(chunk))
+		}
+	}
+	v.Set(items)
+
+	return nil
+}
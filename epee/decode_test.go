@@ -0,0 +1,208 @@
+package epee
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type decodeTestSuite struct {
+	suite.Suite
+}
+
+func TestDecodeTestSuite(t *testing.T) {
+	suite.Run(t, new(decodeTestSuite))
+}
+
+func (s *decodeTestSuite) TestScalars() {
+	var v scalars
+	if assert.NoError(s.T(), Unmarshal(golden(scalarsGolden), &v)) {
+		assert.Equal(s.T(), scalarsValue, v)
+	}
+}
+
+func (s *decodeTestSuite) TestNested() {
+	var v nested
+	if assert.NoError(s.T(), Unmarshal(golden(nestedGolden), &v)) {
+		assert.Equal(s.T(), nestedValue, v)
+	}
+}
+
+func (s *decodeTestSuite) TestInterface() {
+	var v interface{}
+	if assert.NoError(s.T(), Unmarshal(golden(nestedGolden), &v)) {
+		assert.Equal(s.T(), map[string]interface{}{
+			"name":   []byte("xmr!"),
+			"o":      map[string]interface{}{"flag": true},
+			"items":  []interface{}{map[string]interface{}{"flag": false}, map[string]interface{}{"flag": true}},
+			"v":      []interface{}{uint32(1), uint32(2)},
+			"n":      []interface{}{[]interface{}{uint16(1)}, []interface{}{uint16(2), uint16(3)}},
+			"hashes": []interface{}{bytes.Repeat([]byte{0x11}, 32)},
+			"ids":    append(bytes.Repeat([]byte{0x22}, 32), bytes.Repeat([]byte{0x33}, 32)...),
+			"empty":  []interface{}{},
+		}, v)
+	}
+
+	var m map[string]interface{}
+	if assert.NoError(s.T(), Unmarshal(golden(scalarsGolden), &m)) {
+		assert.Equal(s.T(), int64(-2), m["i64"])
+		assert.Equal(s.T(), int8(-5), m["i8"])
+		assert.Equal(s.T(), uint16(3), m["u16"])
+		assert.Equal(s.T(), 1.5, m["f"])
+	}
+}
+
+func (s *decodeTestSuite) TestConversions() {
+	var v struct {
+		I64 int     `epee:"u8"`
+		U64 uint64  `epee:"i16"`
+		U8  uint8   `epee:"u64"`
+		S   []byte  `epee:"s"`
+		P   *string `epee:"name"`
+	}
+
+	err := Unmarshal(golden(scalarsGolden), &v)
+	assert.EqualError(s.T(), err, "i16: Value -4 overflows uint64")
+
+	v.U64 = 0
+	data := golden("0c 02 7538 08 02 01 73 0a 0c616263 04 6e616d65 0a 04 78")
+	if assert.NoError(s.T(), Unmarshal(data, &v)) {
+		assert.Equal(s.T(), 2, v.I64)
+		assert.Equal(s.T(), []byte("abc"), v.S)
+		if assert.NotNil(s.T(), v.P) {
+			assert.Equal(s.T(), "x", *v.P)
+		}
+	}
+
+	var small struct {
+		U8 uint8 `epee:"u64"`
+	}
+	assert.EqualError(s.T(), Unmarshal(golden(scalarsGolden), &small), "u64: Value 1000 overflows uint8")
+}
+
+func (s *decodeTestSuite) TestUnknownEntries() {
+	var v struct {
+		B bool `epee:"b"`
+	}
+
+	if assert.NoError(s.T(), Unmarshal(golden(nestedGolden), &v)) {
+		assert.False(s.T(), v.B)
+	}
+	if assert.NoError(s.T(), Unmarshal(golden(scalarsGolden), &v)) {
+		assert.True(s.T(), v.B)
+	}
+}
+
+type flexible struct {
+	Blob []byte
+	Flag bool
+}
+
+func (f *flexible) UnmarshalEpee(v interface{}) error {
+	switch v := v.(type) {
+	case []byte:
+		f.Blob = v
+	case map[string]interface{}:
+		f.Flag, _ = v["flag"].(bool)
+	default:
+		return errors.New("Unexpected value")
+	}
+
+	return nil
+}
+
+func (s *decodeTestSuite) TestUnmarshaler() {
+	var v struct {
+		Name  flexible   `epee:"name"`
+		Items []flexible `epee:"items"`
+		Obj   *flexible  `epee:"o"`
+		Bad   flexible   `epee:"v"`
+	}
+
+	err := Unmarshal(golden(nestedGolden), &v)
+	assert.EqualError(s.T(), err, "v: Unexpected value")
+	assert.Equal(s.T(), []byte("xmr!"), v.Name.Blob)
+	assert.Equal(s.T(), []flexible{{}, {Flag: true}}, v.Items)
+	if assert.NotNil(s.T(), v.Obj) {
+		assert.True(s.T(), v.Obj.Flag)
+	}
+}
+
+func (s *decodeTestSuite) TestErrors() {
+	var v interface{}
+
+	assert.Equal(s.T(), ErrTruncated, Unmarshal(header[:5], &v))
+	assert.Equal(s.T(), ErrInvalidHeader, Unmarshal([]byte{1, 0x11, 1, 1, 1, 1, 2, 1, 2, 0}, &v))
+	assert.Equal(s.T(), ErrTruncated, Unmarshal(header, &v))
+	assert.Error(s.T(), Unmarshal(golden("00"), v))
+	assert.Error(s.T(), Unmarshal(golden("00 00"), &v))
+
+	data := golden(scalarsGolden)
+	for i := len(header); i < len(data); i++ {
+		assert.Error(s.T(), Unmarshal(data[:i], &v), "%d", i)
+	}
+
+	assert.EqualError(s.T(), Unmarshal(golden("04 01 61 0f 00"), &v), "a: Unknown portable storage type: 15")
+	assert.Equal(s.T(), ErrTruncated, errors.Unwrap(Unmarshal(golden("04 01 61 8a fe ff ff ff"), &v)), "Count must be checked against the data size.")
+
+	assert.Equal(s.T(), ErrTruncated, errors.Unwrap(Unmarshal(golden("04 01 61 8d 08 87 04 0100 87 08 0200"), &v)), "Errors of nested arrays must be reported.")
+
+	deep := bytes.Repeat([]byte{0x04, 0x01, 0x61, 0x0c}, maxDepth+1)
+	deep = append(deep, 0x00)
+	assert.True(s.T(), errors.Is(Unmarshal(golden(hexString(deep)), &v), ErrTooDeep))
+
+	var h struct {
+		H [32]byte `epee:"s"`
+	}
+	assert.Error(s.T(), Unmarshal(golden(scalarsGolden), &h))
+}
+
+func hexString(b []byte) string {
+	const digits = "0123456789abcdef"
+
+	var buf bytes.Buffer
+	for _, c := range b {
+		buf.WriteByte(digits[c>>4])
+		buf.WriteByte(digits[c&0xf])
+	}
+
+	return buf.String()
+}
+
+func FuzzUnmarshal(f *testing.F) {
+	f.Add(golden(scalarsGolden))
+	f.Add(golden(nestedGolden))
+	f.Add(golden("0c 01 61 0a 04 78 01 62 08 01 01 63 0c 00"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var v interface{}
+		if err := Unmarshal(data, &v); err != nil {
+			return
+		}
+
+		first, err := Marshal(v)
+		if err != nil {
+			t.Fatalf("Marshal of decoded value: %v", err)
+		}
+
+		var w interface{}
+		if err := Unmarshal(first, &w); err != nil {
+			t.Fatalf("Unmarshal of encoded value: %v", err)
+		}
+
+		second, err := Marshal(w)
+		if err != nil {
+			t.Fatalf("Marshal of decoded value: %v", err)
+		}
+
+		if !bytes.Equal(first, second) {
+			t.Fatalf("Encoding is not stable: %x != %x", first, second)
+		}
+
+		var n nested
+		Unmarshal(data, &n)
+	})
+}
@@ -0,0 +1,325 @@
+package epee
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// Marshal returns the portable storage encoding of v, which must be a struct
+// or a map with string keys (or a pointer to one).
+func Marshal(v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("Unsupported portable storage root: %T", v)
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct && !(rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String) {
+		return nil, fmt.Errorf("Unsupported portable storage root: %T", v)
+	}
+
+	e := &encoder{buf: append([]byte(nil), header...)}
+	if err := e.section(rv); err != nil {
+		return nil, err
+	}
+
+	return e.buf, nil
+}
+
+type encoder struct {
+	buf   []byte
+	depth int
+}
+
+type entry struct {
+	name  string
+	value reflect.Value
+	blob  bool
+}
+
+func (e *encoder) section(v reflect.Value) error {
+	if e.depth++; e.depth > maxDepth {
+		return ErrTooDeep
+	}
+	defer func() { e.depth-- }()
+
+	var entries []entry
+	if v.Kind() == reflect.Map {
+		for _, k := range v.MapKeys() {
+			entries = append(entries, entry{name: k.String(), value: v.MapIndex(k)})
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+	} else {
+		for _, f := range fields(v.Type()) {
+			fv, ok := fieldByIndex(v, f.index)
+			if !ok || (f.omitEmpty && fv.IsZero()) {
+				continue
+			}
+			entries = append(entries, entry{name: f.name, value: fv, blob: f.blob})
+		}
+	}
+
+	var valid []entry
+	for _, en := range entries {
+		if _, ok := valueType(indirect(en.value), en.blob); ok {
+			valid = append(valid, en)
+		}
+	}
+
+	var err error
+	if e.buf, err = appendVarint(e.buf, uint64(len(valid))); err != nil {
+		return err
+	}
+
+	for _, en := range valid {
+		if len(en.name) > 255 {
+			return fmt.Errorf("Portable storage entry name is too long: %s", en.name)
+		}
+
+		e.buf = append(e.buf, byte(len(en.name)))
+		e.buf = append(e.buf, en.name...)
+		if err := e.entry(indirect(en.value), en.blob); err != nil {
+			return fmt.Errorf("%s: %w", en.name, err)
+		}
+	}
+
+	return nil
+}
+
+func (e *encoder) entry(v reflect.Value, blob bool) error {
+	typ, _ := valueType(v, blob)
+	e.buf = append(e.buf, typ)
+
+	if typ&flagArray != 0 {
+		return e.array(v, typ&^flagArray)
+	}
+
+	return e.value(v, typ, blob)
+}
+
+func (e *encoder) array(v reflect.Value, typ byte) error {
+	var err error
+	if e.buf, err = appendVarint(e.buf, uint64(v.Len())); err != nil {
+		return err
+	}
+
+	for i := 0; i < v.Len(); i++ {
+		item := indirect(v.Index(i))
+		t, ok := valueType(item, false)
+		if !ok && typ == typeArray && item.Kind() == reflect.Slice && item.Len() == 0 {
+			// The type of an empty []interface{} is unknown, any array type will do.
+			e.buf = append(e.buf, typeUint8|flagArray, 0)
+			continue
+		}
+		if t&flagArray != 0 {
+			t = typeArray
+		}
+		if !ok || t != typ {
+			return fmt.Errorf("Inconsistent portable storage array item %d", i)
+		}
+
+		if typ == typeArray {
+			if err := e.entry(item, false); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := e.value(item, typ, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *encoder) value(v reflect.Value, typ byte, blob bool) error {
+	switch typ {
+	case typeInt64:
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, uint64(v.Int()))
+	case typeInt32:
+		e.buf = binary.LittleEndian.AppendUint32(e.buf, uint32(v.Int()))
+	case typeInt16:
+		e.buf = binary.LittleEndian.AppendUint16(e.buf, uint16(v.Int()))
+	case typeInt8:
+		e.buf = append(e.buf, byte(v.Int()))
+	case typeUint64:
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, v.Uint())
+	case typeUint32:
+		e.buf = binary.LittleEndian.AppendUint32(e.buf, uint32(v.Uint()))
+	case typeUint16:
+		e.buf = binary.LittleEndian.AppendUint16(e.buf, uint16(v.Uint()))
+	case typeUint8:
+		e.buf = append(e.buf, byte(v.Uint()))
+	case typeDouble:
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(v.Float()))
+	case typeBool:
+		if v.Bool() {
+			e.buf = append(e.buf, 1)
+		} else {
+			e.buf = append(e.buf, 0)
+		}
+	case typeString:
+		return e.string(v, blob)
+	case typeObject:
+		return e.section(v)
+	}
+
+	return nil
+}
+
+func (e *encoder) string(v reflect.Value, blob bool) error {
+	var data []byte
+	switch {
+	case v.Kind() == reflect.String:
+		data = []byte(v.String())
+	case isBytes(v.Type()):
+		data = make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(data), v)
+	case blob:
+		var err error
+		if data, err = packBlob(v); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if e.buf, err = appendVarint(e.buf, uint64(len(data))); err != nil {
+		return err
+	}
+	e.buf = append(e.buf, data...)
+
+	return nil
+}
+
+func packBlob(v reflect.Value) ([]byte, error) {
+	var data []byte
+	for i := 0; i < v.Len(); i++ {
+		item := v.Index(i)
+		switch item.Kind() {
+		case reflect.Uint8:
+			data = append(data, byte(item.Uint()))
+		case reflect.Uint16:
+			data = binary.LittleEndian.AppendUint16(data, uint16(item.Uint()))
+		case reflect.Uint32:
+			data = binary.LittleEndian.AppendUint32(data, uint32(item.Uint()))
+		case reflect.Uint64:
+			data = binary.LittleEndian.AppendUint64(data, item.Uint())
+		case reflect.Array:
+			if item.Type().Elem().Kind() != reflect.Uint8 {
+				return nil, fmt.Errorf("Unsupported blob item: %s", item.Type())
+			}
+			for j := 0; j < item.Len(); j++ {
+				data = append(data, byte(item.Index(j).Uint()))
+			}
+		default:
+			return nil, fmt.Errorf("Unsupported blob item: %s", item.Type())
+		}
+	}
+
+	return data, nil
+}
+
+// valueType returns the epee type of v, false when v cannot be encoded and is skipped.
+func valueType(v reflect.Value, blob bool) (byte, bool) {
+	if !v.IsValid() {
+		return 0, false
+	}
+
+	if blob && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) {
+		return typeString, true
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int64:
+		return typeInt64, true
+	case reflect.Int32:
+		return typeInt32, true
+	case reflect.Int16:
+		return typeInt16, true
+	case reflect.Int8:
+		return typeInt8, true
+	case reflect.Uint, reflect.Uint64:
+		return typeUint64, true
+	case reflect.Uint32:
+		return typeUint32, true
+	case reflect.Uint16:
+		return typeUint16, true
+	case reflect.Uint8:
+		return typeUint8, true
+	case reflect.Float32, reflect.Float64:
+		return typeDouble, true
+	case reflect.String:
+		return typeString, true
+	case reflect.Bool:
+		return typeBool, true
+	case reflect.Struct:
+		return typeObject, true
+	case reflect.Map:
+		return typeObject, v.Type().Key().Kind() == reflect.String
+	case reflect.Slice, reflect.Array:
+		if isBytes(v.Type()) {
+			return typeString, true
+		}
+		if v.Len() == 0 {
+			return elemType(v.Type().Elem())
+		}
+		typ, ok := valueType(indirect(v.Index(0)), false)
+		if typ&flagArray != 0 {
+			typ = typeArray
+		}
+		return typ | flagArray, ok
+	}
+
+	return 0, false
+}
+
+// elemType returns the array type of empty slices from their static element type.
+func elemType(t reflect.Type) (byte, bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Interface {
+		return 0, false
+	}
+
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && !isBytes(t) {
+		return typeArray | flagArray, true
+	}
+
+	typ, ok := valueType(reflect.Zero(t), false)
+	return typ | flagArray, ok
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+
+	return v
+}
+
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 {
+			if v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					return reflect.Value{}, false
+				}
+				v = v.Elem()
+			}
+		}
+		v = v.Field(x)
+	}
+
+	return v, true
+}
@@ -0,0 +1,160 @@
+package epee
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type encodeTestSuite struct {
+	suite.Suite
+}
+
+func TestEncodeTestSuite(t *testing.T) {
+	suite.Run(t, new(encodeTestSuite))
+}
+
+// golden returns the header followed by the hex encoded body, spaces are ignored.
+func golden(body string) []byte {
+	b, err := hex.DecodeString(strings.ReplaceAll(body, " ", ""))
+	if err != nil {
+		panic(err)
+	}
+
+	return append(append([]byte(nil), header...), b...)
+}
+
+type scalars struct {
+	I64 int64   `epee:"i64"`
+	I32 int32   `epee:"i32"`
+	I16 int16   `epee:"i16"`
+	I8  int8    `epee:"i8"`
+	U64 uint64  `epee:"u64"`
+	U32 uint32  `epee:"u32"`
+	U16 uint16  `epee:"u16"`
+	U8  uint8   `epee:"u8"`
+	F   float64 `epee:"f"`
+	S   string  `epee:"s"`
+	B   bool    `epee:"b"`
+}
+
+var scalarsGolden = "2c" +
+	"03 693634 01 feffffffffffffff" +
+	"03 693332 02 fdffffff" +
+	"03 693136 03 fcff" +
+	"02 6938 04 fb" +
+	"03 753634 05 e803000000000000" +
+	"03 753332 06 04000000" +
+	"03 753136 07 0300" +
+	"02 7538 08 02" +
+	"01 66 09 000000000000f83f" +
+	"01 73 0a 0c616263" +
+	"01 62 0b 01"
+
+var scalarsValue = scalars{I64: -2, I32: -3, I16: -4, I8: -5, U64: 1000, U32: 4, U16: 3, U8: 2, F: 1.5, S: "abc", B: true}
+
+type nested struct {
+	Name   string     `epee:"name"`
+	Object inner      `epee:"o"`
+	Items  []inner    `epee:"items"`
+	Ints   []uint32   `epee:"v"`
+	Nested [][]uint16 `epee:"n"`
+	Hashes [][32]byte `epee:"hashes"`
+	IDs    [][32]byte `epee:"ids,blob"`
+	Empty  []uint64   `epee:"empty"`
+	Skip   string     `epee:"-"`
+	Omit   string     `epee:"omit,omitempty"`
+}
+
+type inner struct {
+	Flag bool `epee:"flag"`
+}
+
+func hash(b byte) [32]byte {
+	var h [32]byte
+	for i := range h {
+		h[i] = b
+	}
+
+	return h
+}
+
+var nestedGolden = "20" +
+	"04 6e616d65 0a 10 78 6d 72 21" +
+	"01 6f 0c 04 04 666c6167 0b 01" +
+	"05 6974656d73 8c 08 04 04 666c6167 0b 00 04 04 666c6167 0b 01" +
+	"01 76 86 08 01000000 02000000" +
+	"01 6e 8d 08 87 04 0100 87 08 0200 0300" +
+	"06 686173686573 8a 04 80" + strings.Repeat("11", 32) +
+	"03 696473 0a 0101" + strings.Repeat("22", 32) + strings.Repeat("33", 32) +
+	"05 656d707479 85 00"
+
+var nestedValue = nested{
+	Name:   "xmr!",
+	Object: inner{Flag: true},
+	Items:  []inner{{}, {Flag: true}},
+	Ints:   []uint32{1, 2},
+	Nested: [][]uint16{{1}, {2, 3}},
+	Hashes: [][32]byte{hash(0x11)},
+	IDs:    [][32]byte{hash(0x22), hash(0x33)},
+	Empty:  []uint64{},
+}
+
+func (s *encodeTestSuite) TestScalars() {
+	data, err := Marshal(scalarsValue)
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), golden(scalarsGolden), data)
+	}
+}
+
+func (s *encodeTestSuite) TestNested() {
+	data, err := Marshal(&nestedValue)
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), hex.EncodeToString(golden(nestedGolden)), hex.EncodeToString(data))
+	}
+}
+
+func (s *encodeTestSuite) TestMap() {
+	data, err := Marshal(map[string]interface{}{"b": uint8(1), "a": "x", "nil": nil, "c": map[string]uint16{}})
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), golden("0c 01 61 0a 04 78 01 62 08 01 01 63 0c 00"), data)
+	}
+}
+
+func (s *encodeTestSuite) TestEmbedded() {
+	type base struct {
+		Height uint64 `epee:"height"`
+	}
+	type request struct {
+		base
+		Client string  `epee:"client,omitempty"`
+		Ptr    *uint32 `epee:"ptr"`
+	}
+
+	data, err := Marshal(request{base: base{Height: 1}})
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), golden("04 06 686569676874 05 0100000000000000"), data)
+	}
+}
+
+func (s *encodeTestSuite) TestErrors() {
+	_, err := Marshal(1)
+	assert.Error(s.T(), err)
+
+	_, err = Marshal(nil)
+	assert.Error(s.T(), err)
+
+	_, err = Marshal(map[string]interface{}{"a": []interface{}{uint8(1), "x"}})
+	assert.Error(s.T(), err)
+
+	_, err = Marshal(map[string]interface{}{strings.Repeat("a", 256): 1})
+	assert.Error(s.T(), err)
+
+	_, err = Marshal(struct {
+		Blob []int `epee:"blob,blob"`
+	}{Blob: []int{1}})
+	assert.Error(s.T(), err)
+}
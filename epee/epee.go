@@ -0,0 +1,118 @@
+// Package epee implements the portable storage binary format of epee, used by
+// the .bin endpoints of monerod.
+//
+// Struct fields are mapped with `epee:"name,omitempty"` tags similar to
+// encoding/json. Go integer, float, bool and string types map to the epee types
+// of the same size, []byte and [N]byte to strings, slices and arrays to epee
+// arrays and structs and maps to sections. The "blob" option stores a slice of
+// fixed size values (hashes, integers) as a single string, like
+// KV_SERIALIZE_CONTAINER_POD_AS_BLOB.
+package epee
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const (
+	typeInt64  byte = 1
+	typeInt32  byte = 2
+	typeInt16  byte = 3
+	typeInt8   byte = 4
+	typeUint64 byte = 5
+	typeUint32 byte = 6
+	typeUint16 byte = 7
+	typeUint8  byte = 8
+	typeDouble byte = 9
+	typeString byte = 10
+	typeBool   byte = 11
+	typeObject byte = 12
+	typeArray  byte = 13
+
+	flagArray byte = 0x80
+
+	maxDepth = 100
+)
+
+// Signature A (0x01011101), signature B (0x01020101) and format version 1.
+var header = []byte{0x01, 0x11, 0x01, 0x01, 0x01, 0x01, 0x02, 0x01, 0x01}
+
+var (
+	ErrInvalidHeader = errors.New("Invalid portable storage header")
+	ErrTruncated     = errors.New("Unexpected end of portable storage data")
+	ErrTooDeep       = errors.New("Portable storage nesting is too deep")
+	ErrVarintRange   = errors.New("Varint value is out of range")
+)
+
+// Unmarshaler is implemented by types that decode themselves from the generic
+// form of a value, as decoded by Unmarshal into an interface{}.
+type Unmarshaler interface {
+	UnmarshalEpee(v interface{}) error
+}
+
+var unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	blob      bool
+}
+
+var fieldCache sync.Map
+
+func fields(t reflect.Type) []field {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.([]field)
+	}
+
+	var result []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("epee")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts := tag, ""
+		if i := strings.IndexByte(tag, ','); i >= 0 {
+			name, opts = tag[:i], tag[i+1:]
+		}
+
+		if sf.Anonymous && name == "" && sf.Type.Kind() == reflect.Struct {
+			for _, f := range fields(sf.Type) {
+				f.index = append([]int{i}, f.index...)
+				result = append(result, f)
+			}
+			continue
+		}
+
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+
+		f := field{name: name, index: []int{i}}
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "omitempty":
+				f.omitEmpty = true
+			case "blob":
+				f.blob = true
+			}
+		}
+		result = append(result, f)
+	}
+
+	fieldCache.Store(t, result)
+	return result
+}
+
+func isBytes(t reflect.Type) bool {
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() == reflect.Uint8
+}
@@ -0,0 +1,47 @@
+package epee
+
+import "encoding/binary"
+
+const maxVarint = 1<<62 - 1
+
+// appendVarint appends v with the size mark in its two low bits:
+// 0 for 1 byte, 1 for 2 bytes, 2 for 4 bytes and 3 for 8 bytes.
+func appendVarint(b []byte, v uint64) ([]byte, error) {
+	switch {
+	case v <= 1<<6-1:
+		return append(b, byte(v<<2)), nil
+	case v <= 1<<14-1:
+		return binary.LittleEndian.AppendUint16(b, uint16(v<<2|1)), nil
+	case v <= 1<<30-1:
+		return binary.LittleEndian.AppendUint32(b, uint32(v<<2|2)), nil
+	case v <= maxVarint:
+		return binary.LittleEndian.AppendUint64(b, v<<2|3), nil
+	}
+
+	return b, ErrVarintRange
+}
+
+func readVarint(b []byte) (uint64, int, error) {
+	if len(b) == 0 {
+		return 0, 0, ErrTruncated
+	}
+
+	size := 1 << (b[0] & 3)
+	if len(b) < size {
+		return 0, 0, ErrTruncated
+	}
+
+	var v uint64
+	switch size {
+	case 1:
+		v = uint64(b[0])
+	case 2:
+		v = uint64(binary.LittleEndian.Uint16(b))
+	case 4:
+		v = uint64(binary.LittleEndian.Uint32(b))
+	case 8:
+		v = binary.LittleEndian.Uint64(b)
+	}
+
+	return v >> 2, size, nil
+}
@@ -0,0 +1,57 @@
+package epee
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type varintTestSuite struct {
+	suite.Suite
+}
+
+func TestVarintTestSuite(t *testing.T) {
+	suite.Run(t, new(varintTestSuite))
+}
+
+func (s *varintTestSuite) TestVectors() {
+	vectors := []struct {
+		value   uint64
+		encoded []byte
+	}{
+		{0, []byte{0x00}},
+		{1, []byte{0x04}},
+		{63, []byte{0xfc}},
+		{64, []byte{0x01, 0x01}},
+		{16383, []byte{0xfd, 0xff}},
+		{16384, []byte{0x02, 0x00, 0x01, 0x00}},
+		{1<<30 - 1, []byte{0xfe, 0xff, 0xff, 0xff}},
+		{1 << 30, []byte{0x03, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00}},
+		{maxVarint, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+	}
+
+	for _, v := range vectors {
+		encoded, err := appendVarint(nil, v.value)
+		if assert.NoError(s.T(), err) {
+			assert.Equal(s.T(), v.encoded, encoded, "%d", v.value)
+		}
+
+		value, n, err := readVarint(append(v.encoded, 0xaa))
+		if assert.NoError(s.T(), err) {
+			assert.Equal(s.T(), v.value, value)
+			assert.Equal(s.T(), len(v.encoded), n)
+		}
+	}
+}
+
+func (s *varintTestSuite) TestErrors() {
+	_, err := appendVarint(nil, maxVarint+1)
+	assert.Equal(s.T(), ErrVarintRange, err)
+
+	_, _, err = readVarint(nil)
+	assert.Equal(s.T(), ErrTruncated, err)
+
+	_, _, err = readVarint([]byte{0x02, 0x00, 0x01})
+	assert.Equal(s.T(), ErrTruncated, err)
+}
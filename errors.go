@@ -0,0 +1,211 @@
+package xmrrpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"syscall"
+)
+
+const httpErrorBodyLimit = 512
+
+var (
+	ErrWrongParam           = &RPCError{Code: -1, Message: "Wrong param"}
+	ErrTooBigHeight         = &RPCError{Code: -2, Message: "Too big height"}
+	ErrTooBigReserveSize    = &RPCError{Code: -3, Message: "Too big reserve size"}
+	ErrWrongWalletAddress   = &RPCError{Code: -4, Message: "Failed to parse wallet address"}
+	ErrInternalError        = &RPCError{Code: -5, Message: "Internal error"}
+	ErrWrongBlockblob       = &RPCError{Code: -6, Message: "Wrong block blob"}
+	ErrBlockNotAccepted     = &RPCError{Code: -7, Message: "Block not accepted"}
+	ErrCoreBusy             = &RPCError{Code: -9, Message: "Core is busy"}
+	ErrWrongBlockblobSize   = &RPCError{Code: -10, Message: "Wrong block blob size"}
+	ErrUnsupportedRPC       = &RPCError{Code: -11, Message: "Unsupported RPC"}
+	ErrMiningToSubaddress   = &RPCError{Code: -12, Message: "Mining to subaddress is not supported"}
+	ErrRegtestRequired      = &RPCError{Code: -13, Message: "Regtest mode required"}
+	ErrPaymentRequired      = &RPCError{Code: -14, Message: "Payment required"}
+	ErrInvalidClient        = &RPCError{Code: -15, Message: "Invalid client"}
+	ErrPaymentTooLow        = &RPCError{Code: -16, Message: "Payment too low"}
+	ErrDuplicatePayment     = &RPCError{Code: -17, Message: "Duplicate payment"}
+	ErrStalePayment         = &RPCError{Code: -18, Message: "Stale payment"}
+	ErrRestricted           = &RPCError{Code: -19, Message: "Restricted RPC"}
+	ErrUnsupportedBootstrap = &RPCError{Code: -20, Message: "Unsupported bootstrap"}
+	ErrPaymentNotSupported  = &RPCError{Code: -21, Message: "Payment not supported"}
+	ErrParse                = &RPCError{Code: -32700, Message: "Parse error"}
+	ErrInvalidRequest       = &RPCError{Code: -32600, Message: "Invalid request"}
+	ErrMethodNotFound       = &RPCError{Code: -32601, Message: "Method not found"}
+	ErrInvalidParams        = &RPCError{Code: -32602, Message: "Invalid params"}
+	ErrInternalJSONRPC      = &RPCError{Code: -32603, Message: "Internal JSON-RPC error"}
+
+	ErrStatusBusy       = &StatusError{Status: "BUSY"}
+	ErrStatusFailed     = &StatusError{Status: "Failed"}
+	ErrStatusNotMining  = &StatusError{Status: "NOT MINING"}
+	ErrStatusPayment    = &StatusError{Status: "PAYMENT REQUIRED"}
+	ErrStatusRestricted = &StatusError{Status: "RESTRICTED"}
+)
+
+type RPCError struct {
+	Code    int
+	Message string
+	Method  string
+}
+
+func (e *RPCError) Error() string {
+	return e.Message
+}
+
+func (e *RPCError) Is(target error) bool {
+	t, ok := target.(*RPCError)
+	return ok && t.Code == e.Code
+}
+
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func newHTTPError(res *http.Response) *HTTPError {
+	body, _ := ioutil.ReadAll(io.LimitReader(res.Body, httpErrorBodyLimit))
+	return &HTTPError{StatusCode: res.StatusCode, Status: res.Status, Body: strings.TrimSpace(string(body))}
+}
+
+func (e *HTTPError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("Unexpected HTTP status: %s", e.Status)
+	}
+
+	return fmt.Sprintf("Unexpected HTTP status: %s: %s", e.Status, e.Body)
+}
+
+type StatusError struct {
+	Method string
+	Status string
+	Reason string
+}
+
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf("Unexpected status: %s", e.Status)
+	if e.Method != "" {
+		msg = fmt.Sprintf("Unexpected status of %s: %s", e.Method, e.Status)
+	}
+
+	if e.Reason != "" {
+		msg += " (" + e.Reason + ")"
+	}
+
+	return msg
+}
+
+func (e *StatusError) Is(target error) bool {
+	t, ok := target.(*StatusError)
+	return ok && strings.EqualFold(t.Status, e.Status)
+}
+
+func checkStatus(method string, reply interface{}) error {
+	status, reason := replyStatus(reply)
+	if status == "" || status == "OK" {
+		return nil
+	}
+
+	return &StatusError{Method: strings.TrimPrefix(method, "/"), Status: status, Reason: reason}
+}
+
+func replyStatus(reply interface{}) (status string, reason string) {
+	if raw, ok := reply.(*json.RawMessage); ok {
+		var res struct {
+			Status string `json:"status"`
+			Reason string `json:"reason"`
+		}
+		if json.Unmarshal(*raw, &res) != nil {
+			return "", ""
+		}
+		reply = &res
+	}
+
+	v := reflect.ValueOf(reply)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", ""
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return "", ""
+	}
+
+	if f := v.FieldByName("Status"); f.IsValid() && f.Kind() == reflect.String {
+		status = f.String()
+	}
+	if f := v.FieldByName("Reason"); f.IsValid() && f.Kind() == reflect.String {
+		reason = f.String()
+	}
+
+	return status, reason
+}
+
+// IsRetryable reports whether err is transient: a network failure, a timeout,
+// a truncated response, HTTP 5xx or 429 and a busy daemon. Anything else, such
+// as a certificate mismatch or an oversized or malformed response, would fail
+// again.
+func IsRetryable(err error) bool {
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Code == ErrCoreBusy.Code
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return errors.Is(statusErr, ErrStatusBusy)
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= http.StatusInternalServerError || httpErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	if isPermanent(err) {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	// url.Error is a net.Error whatever it wraps.
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// isPermanent reports errors which may come wrapped in a net.Error, e.g. a TLS
+// failure behind a proxy.
+func isPermanent(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, ErrResponseTooLarge) || errors.Is(err, ErrProxyRequired) || errors.Is(err, ErrCertificateNotAllowed) {
+		return true
+	}
+
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	var verification *tls.CertificateVerificationError
+	var record tls.RecordHeaderError
+
+	return errors.As(err, &unknownAuthority) || errors.As(err, &hostname) || errors.As(err, &invalid) ||
+		errors.As(err, &verification) || errors.As(err, &record)
+}
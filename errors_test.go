@@ -0,0 +1,117 @@
+package xmrrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type errorsTestSuite struct {
+	suite.Suite
+}
+
+func TestErrorsTestSuite(t *testing.T) {
+	suite.Run(t, new(errorsTestSuite))
+}
+
+func (s *errorsTestSuite) TestRPCError() {
+	err := fmt.Errorf("wrapped: %w", &RPCError{Code: -7, Message: "Block not accepted", Method: "submit_block"})
+	assert.True(s.T(), errors.Is(err, ErrBlockNotAccepted))
+	assert.False(s.T(), errors.Is(err, ErrCoreBusy))
+
+	var rpcErr *RPCError
+	if assert.True(s.T(), errors.As(err, &rpcErr)) {
+		assert.Equal(s.T(), -7, rpcErr.Code)
+		assert.Equal(s.T(), "submit_block", rpcErr.Method)
+		assert.EqualError(s.T(), rpcErr, "Block not accepted")
+	}
+}
+
+func (s *errorsTestSuite) TestStatusError() {
+	err := &StatusError{Method: "start_mining", Status: "BUSY"}
+	assert.True(s.T(), errors.Is(err, ErrStatusBusy))
+	assert.False(s.T(), errors.Is(err, ErrStatusFailed))
+	assert.EqualError(s.T(), err, "Unexpected status of start_mining: BUSY")
+	assert.EqualError(s.T(), ErrStatusFailed, "Unexpected status: Failed")
+}
+
+func (s *errorsTestSuite) TestHTTPError() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/get_height" {
+			http.Error(w, strings.Repeat("x", 1024), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	_, err := NewDaemonClient(ts.URL, "username", "password").GetHeight()
+	var httpErr *HTTPError
+	if assert.True(s.T(), errors.As(err, &httpErr)) {
+		assert.Equal(s.T(), http.StatusInternalServerError, httpErr.StatusCode)
+		assert.Len(s.T(), httpErr.Body, httpErrorBodyLimit)
+	}
+
+	_, err = NewDaemonClient(ts.URL, "username", "password").GetInfo()
+	if assert.True(s.T(), errors.As(err, &httpErr)) {
+		assert.Equal(s.T(), http.StatusForbidden, httpErr.StatusCode)
+		assert.EqualError(s.T(), httpErr, "Unexpected HTTP status: 403 Forbidden")
+	}
+}
+
+func (s *errorsTestSuite) TestCheckStatus() {
+	assert.NoError(s.T(), checkStatus("get_info", nil))
+	assert.NoError(s.T(), checkStatus("on_get_block_hash", new(string)))
+	assert.NoError(s.T(), checkStatus("get_info", &InfoResponse{Status: "OK"}))
+	assert.NoError(s.T(), checkStatus("get_info", &InfoResponse{}))
+
+	err := checkStatus("/send_raw_transaction", &SendRawTransactionResponse{Status: "Failed", Reason: "double spend"})
+	var statusErr *StatusError
+	if assert.True(s.T(), errors.As(err, &statusErr)) {
+		assert.Equal(s.T(), "send_raw_transaction", statusErr.Method)
+		assert.Equal(s.T(), "double spend", statusErr.Reason)
+		assert.EqualError(s.T(), err, "Unexpected status of send_raw_transaction: Failed (double spend)")
+	}
+}
+
+func (s *errorsTestSuite) TestStatusCheck() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json_rpc":
+			req := &jsonRPCRequest{}
+			json.NewDecoder(r.Body).Decode(req)
+			w.Write([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"status":"BUSY"}}`, req.ID)))
+		case "/send_raw_transaction":
+			w.Write([]byte(`{"status":"Failed","reason":"Fee too low","fee_too_low":true}`))
+		default:
+			w.Write([]byte(`{"status":"Failed"}`))
+		}
+	}))
+	defer ts.Close()
+
+	_, err := NewDaemonClient(ts.URL, "username", "password").GetInfo()
+	assert.True(s.T(), errors.Is(err, ErrStatusBusy))
+
+	res, err := NewDaemonClient(ts.URL, "username", "password").SendRawTransaction("", false)
+	if assert.True(s.T(), errors.Is(err, ErrStatusFailed)) {
+		assert.Contains(s.T(), err.Error(), "Fee too low")
+		assert.True(s.T(), res.FeeTooLow)
+	}
+
+	_, err = NewDaemonClient(ts.URL, "username", "password").StartMining(false, false, "", 1)
+	assert.True(s.T(), errors.Is(err, ErrStatusFailed))
+
+	res, err = NewDaemonClient(ts.URL, "username", "password", WithoutStatusCheck()).SendRawTransaction("", false)
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), "Failed", res.Status)
+		assert.Equal(s.T(), "Fee too low", res.Reason)
+	}
+}
@@ -0,0 +1,34 @@
+package xmrrpc
+
+import "context"
+
+type Invoker func(ctx context.Context, inv *Invocation) error
+
+// Interceptor wraps a call, next performs it (including retries) and
+// leaves the decoded reply in inv.Result.
+type Interceptor func(ctx context.Context, inv *Invocation, next Invoker) error
+
+// WithInterceptors appends interceptors to the chain, the first one is the outermost.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(dc *DaemonClient) {
+		dc.interceptors = append(dc.interceptors, interceptors...)
+	}
+}
+
+func (inv *Invocation) IsJSONRPC() bool {
+	return inv.Endpoint == "/json_rpc"
+}
+
+func (dc *DaemonClient) invoke(ctx context.Context, inv *Invocation) error {
+	return dc.chain(0)(ctx, inv)
+}
+
+func (dc *DaemonClient) chain(i int) Invoker {
+	if i == len(dc.interceptors) {
+		return dc.dispatch
+	}
+
+	return func(ctx context.Context, inv *Invocation) error {
+		return dc.interceptors[i](ctx, inv, dc.chain(i+1))
+	}
+}
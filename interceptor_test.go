@@ -0,0 +1,122 @@
+package xmrrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type interceptorTestSuite struct {
+	suite.Suite
+	ts     *httptest.Server
+	header http.Header
+	calls  int32
+}
+
+func TestInterceptorTestSuite(t *testing.T) {
+	suite.Run(t, new(interceptorTestSuite))
+}
+
+func (s *interceptorTestSuite) SetupTest() {
+	s.calls = 0
+	s.ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.calls, 1)
+		s.header = r.Header
+
+		if r.URL.Path == "/json_rpc" {
+			var req jsonRPCRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			w.Write([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"status":"OK","count":42}}`, req.ID)))
+			return
+		}
+
+		w.Write([]byte(`{"status":"OK","height":100}`))
+	}))
+}
+
+func (s *interceptorTestSuite) TearDownTest() {
+	s.ts.Close()
+}
+
+func (s *interceptorTestSuite) TestChain() {
+	var trace []string
+	record := func(name string) Interceptor {
+		return func(ctx context.Context, inv *Invocation, next Invoker) error {
+			trace = append(trace, name+" "+inv.Method)
+			err := next(ctx, inv)
+			trace = append(trace, name+" done")
+			return err
+		}
+	}
+
+	dc := NewDaemonClient(s.ts.URL, "username", "password", WithInterceptors(record("a"), record("b")), WithInterceptors(record("c")))
+
+	_, err := dc.GetBlockCount()
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"a get_block_count", "b get_block_count", "c get_block_count", "c done", "b done", "a done"}, trace)
+}
+
+func (s *interceptorTestSuite) TestInvocation() {
+	var invs []Invocation
+	dc := NewDaemonClient(s.ts.URL, "username", "password", WithInterceptors(func(ctx context.Context, inv *Invocation, next Invoker) error {
+		err := next(ctx, inv)
+		invs = append(invs, *inv)
+		return err
+	}))
+
+	count, err := dc.GetBlockCount()
+	assert.NoError(s.T(), err)
+	height, err := dc.GetHeight()
+	assert.NoError(s.T(), err)
+
+	if assert.Len(s.T(), invs, 2) {
+		assert.Equal(s.T(), "get_block_count", invs[0].Method)
+		assert.Equal(s.T(), "/json_rpc", invs[0].Endpoint)
+		assert.True(s.T(), invs[0].IsJSONRPC())
+		assert.Equal(s.T(), uint(42), invs[0].Result.(*BlockCountResponse).Count)
+		assert.Equal(s.T(), count, *invs[0].Result.(*BlockCountResponse))
+
+		assert.Equal(s.T(), "get_height", invs[1].Method)
+		assert.Equal(s.T(), "/get_height", invs[1].Endpoint)
+		assert.False(s.T(), invs[1].IsJSONRPC())
+		assert.Equal(s.T(), height, *invs[1].Result.(*HeightResponse))
+	}
+}
+
+func (s *interceptorTestSuite) TestHeader() {
+	dc := NewDaemonClient(s.ts.URL, "username", "password", WithUserAgent("xmrrpc-test"), WithInterceptors(func(ctx context.Context, inv *Invocation, next Invoker) error {
+		inv.Header = http.Header{"x-request-id": {"1"}}
+		return next(ctx, inv)
+	}))
+
+	_, err := dc.GetHeight()
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "1", s.header.Get("X-Request-Id"))
+	assert.Equal(s.T(), "xmrrpc-test", s.header.Get("User-Agent"))
+	assert.Empty(s.T(), dc.header.Get("X-Request-Id"))
+}
+
+func (s *interceptorTestSuite) TestFaultInjection() {
+	fault := errors.New("injected")
+	dc := NewDaemonClient(s.ts.URL, "username", "password", WithInterceptors(func(ctx context.Context, inv *Invocation, next Invoker) error {
+		if inv.Method == "get_height" {
+			return fault
+		}
+		return next(ctx, inv)
+	}))
+
+	_, err := dc.GetHeight()
+	assert.Equal(s.T(), fault, err)
+	assert.Zero(s.T(), atomic.LoadInt32(&s.calls))
+
+	_, err = dc.GetBlockCount()
+	assert.NoError(s.T(), err)
+}
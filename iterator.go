@@ -0,0 +1,193 @@
+package xmrrpc
+
+import (
+	"context"
+)
+
+// BlockIterator pages through the chain with get_blocks.bin, it stops once the
+// current height reported by the daemon is reached.
+type BlockIterator struct {
+	dc        *DaemonClient
+	ctx       context.Context
+	prune     bool
+	noMinerTx bool
+
+	next    uint
+	start   uint
+	blocks  []BlockCompleteEntry
+	indices []BlockOutputIndices
+	pos     int
+	done    bool
+	err     error
+}
+
+func (dc *DaemonClient) IterateBlocks(ctx context.Context, startHeight uint, prune bool, noMinerTx bool) *BlockIterator {
+	return &BlockIterator{dc: dc, ctx: ctx, prune: prune, noMinerTx: noMinerTx, next: startHeight, pos: -1}
+}
+
+func (it *BlockIterator) Next() bool {
+	if it.pos+1 < len(it.blocks) {
+		it.pos++
+		return true
+	}
+
+	if it.done || it.err != nil {
+		return false
+	}
+
+	it.blocks, it.indices, it.pos = nil, nil, -1
+
+	// monerod ignores block_ids for a non-zero start height and requires the
+	// genesis hash otherwise.
+	var blockIDs []Hash
+	if it.next == 0 {
+		genesis, err := it.dc.blockHash(it.ctx, 0)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		blockIDs = []Hash{*genesis}
+	}
+
+	res, err := it.dc.GetBlocksBinContext(it.ctx, it.next, blockIDs, it.prune, it.noMinerTx, BlocksOnly, 0)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	if len(res.Blocks) == 0 {
+		it.done = true
+		return false
+	}
+
+	it.start, it.blocks, it.indices, it.pos = res.StartHeight, res.Blocks, res.OutputIndices, 0
+	it.next = res.StartHeight + uint(len(res.Blocks))
+	it.done = it.next >= res.CurrentHeight
+
+	return true
+}
+
+func (it *BlockIterator) Block() BlockCompleteEntry {
+	return it.blocks[it.pos]
+}
+
+func (it *BlockIterator) OutputIndices() BlockOutputIndices {
+	if it.pos < len(it.indices) {
+		return it.indices[it.pos]
+	}
+
+	return BlockOutputIndices{}
+}
+
+func (it *BlockIterator) Height() uint {
+	return it.start + uint(it.pos)
+}
+
+func (it *BlockIterator) Err() error {
+	return it.err
+}
+
+// HashIterator pages through the block hashes of the main chain with
+// get_hashes.bin.
+type HashIterator struct {
+	dc  *DaemonClient
+	ctx context.Context
+
+	next    uint
+	genesis *Hash
+	last    *Hash
+	start   uint
+	hashes  []Hash
+	pos     int
+	done    bool
+	err     error
+}
+
+func (dc *DaemonClient) IterateHashes(ctx context.Context, startHeight uint) *HashIterator {
+	return &HashIterator{dc: dc, ctx: ctx, next: startHeight, pos: -1}
+}
+
+func (it *HashIterator) Next() bool {
+	if it.pos+1 < len(it.hashes) {
+		it.pos++
+		return true
+	}
+
+	if it.done || it.err != nil {
+		return false
+	}
+
+	it.hashes, it.pos = nil, -1
+
+	if it.genesis == nil {
+		if it.genesis, it.err = it.dc.blockHash(it.ctx, 0); it.err != nil {
+			return false
+		}
+	}
+
+	// The daemon starts from the first of block_ids found in the main chain,
+	// the hash preceding the next height keeps pages short.
+	if it.last == nil && it.next > 0 {
+		if it.last, it.err = it.dc.blockHash(it.ctx, it.next-1); it.err != nil {
+			return false
+		}
+	}
+
+	blockIDs := []Hash{*it.genesis}
+	if it.last != nil {
+		blockIDs = []Hash{*it.last, *it.genesis}
+	}
+
+	res, err := it.dc.GetHashesBinContext(it.ctx, blockIDs, it.next)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	start, hashes := res.StartHeight, res.BlockIDs
+	if start < it.next {
+		skip := it.next - start
+		if skip > uint(len(hashes)) {
+			skip = uint(len(hashes))
+		}
+		start, hashes = it.next, hashes[skip:]
+	}
+
+	if len(hashes) == 0 {
+		it.done = true
+		return false
+	}
+
+	it.start, it.hashes, it.pos = start, hashes, 0
+	it.next = start + uint(len(hashes))
+	it.last = &hashes[len(hashes)-1]
+	it.done = it.next >= res.CurrentHeight
+
+	return true
+}
+
+func (it *HashIterator) Hash() Hash {
+	return it.hashes[it.pos]
+}
+
+func (it *HashIterator) Height() uint {
+	return it.start + uint(it.pos)
+}
+
+func (it *HashIterator) Err() error {
+	return it.err
+}
+
+func (dc *DaemonClient) blockHash(ctx context.Context, height uint) (*Hash, error) {
+	s, err := dc.OnGetBlockHashContext(ctx, int(height))
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := ParseHash(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return &h, nil
+}
@@ -0,0 +1,193 @@
+package xmrrpc
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stdfox/xmrrpc/epee"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const genesisHash = "418015bb9ae982a1975da7d79277c2705727a56894ba0fb246adaabb1f4632e3"
+
+type iteratorTestSuite struct {
+	suite.Suite
+	ts       *httptest.Server
+	height   uint
+	page     uint
+	requests []map[string]interface{}
+}
+
+func (s *iteratorTestSuite) SetupTest() {
+	s.height, s.page, s.requests = 7, 3, nil
+	s.ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/json_rpc" {
+			var req struct {
+				ID     uint64 `json:"id"`
+				Params []uint `json:"params"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": req.ID, "jsonrpc": "2.0", "result": s.hash(req.Params[0]).String()})
+			return
+		}
+
+		var req map[string]interface{}
+		body, _ := ioutil.ReadAll(r.Body)
+		if err := epee.Unmarshal(body, &req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.requests = append(s.requests, req)
+
+		if r.URL.Path == "/get_hashes.bin" {
+			var ids []Hash
+			epee.Unmarshal(body, &struct {
+				BlockIDs *[]Hash `epee:"block_ids,blob"`
+			}{&ids})
+
+			res := HashesBinResponse{StartHeight: s.height, CurrentHeight: s.height, Status: "OK"}
+			for h := uint(0); h < s.height; h++ {
+				if len(ids) > 0 && s.hash(h) == ids[0] {
+					res.StartHeight = h
+				}
+			}
+			for h := res.StartHeight; h < s.height && h < res.StartHeight+s.page; h++ {
+				res.BlockIDs = append(res.BlockIDs, s.hash(h))
+			}
+
+			data, _ := epee.Marshal(res)
+			w.Write(data)
+			return
+		}
+
+		res := BlocksBinResponse{StartHeight: uint(req["start_height"].(uint64)), CurrentHeight: s.height, Status: "OK"}
+		for h := res.StartHeight; h < s.height && h < res.StartHeight+s.page; h++ {
+			res.Blocks = append(res.Blocks, BlockCompleteEntry{Block: []byte{byte(h)}})
+			res.OutputIndices = append(res.OutputIndices, BlockOutputIndices{Indices: []TxOutputIndices{{Indices: []uint{h}}}})
+		}
+
+		data, _ := epee.Marshal(res)
+		w.Write(data)
+	}))
+}
+
+func (s *iteratorTestSuite) TearDownTest() {
+	s.ts.Close()
+}
+
+func (s *iteratorTestSuite) hash(height uint) Hash {
+	if height == 0 {
+		h, _ := ParseHash(genesisHash)
+		return h
+	}
+
+	return Hash{byte(height)}
+}
+
+func TestIteratorTestSuite(t *testing.T) {
+	suite.Run(t, new(iteratorTestSuite))
+}
+
+func (s *iteratorTestSuite) TestIterateBlocks() {
+	it := NewDaemonClient(s.ts.URL, "", "").IterateBlocks(context.Background(), 0, false, false)
+
+	var heights []uint
+	for it.Next() {
+		assert.Equal(s.T(), []byte{byte(it.Height())}, it.Block().Block)
+		assert.Equal(s.T(), []uint{it.Height()}, it.OutputIndices().Indices[0].Indices)
+		heights = append(heights, it.Height())
+	}
+
+	assert.NoError(s.T(), it.Err())
+	assert.Equal(s.T(), []uint{0, 1, 2, 3, 4, 5, 6}, heights)
+	assert.Len(s.T(), s.requests, 3)
+
+	genesis, _ := ParseHash(genesisHash)
+	assert.Equal(s.T(), genesis[:], s.requests[0]["block_ids"])
+	assert.Equal(s.T(), uint64(3), s.requests[1]["start_height"])
+	assert.Equal(s.T(), []byte{}, s.requests[1]["block_ids"])
+	assert.Equal(s.T(), uint64(6), s.requests[2]["start_height"])
+	assert.False(s.T(), it.Next())
+}
+
+func (s *iteratorTestSuite) TestIterateBlocksFromHeight() {
+	it := NewDaemonClient(s.ts.URL, "", "").IterateBlocks(context.Background(), 5, true, true)
+
+	var heights []uint
+	for it.Next() {
+		heights = append(heights, it.Height())
+	}
+
+	assert.NoError(s.T(), it.Err())
+	assert.Equal(s.T(), []uint{5, 6}, heights)
+	assert.Len(s.T(), s.requests, 1)
+	assert.Equal(s.T(), true, s.requests[0]["prune"])
+	assert.Equal(s.T(), true, s.requests[0]["no_miner_tx"])
+}
+
+func (s *iteratorTestSuite) TestIterateBlocksEmpty() {
+	it := NewDaemonClient(s.ts.URL, "", "").IterateBlocks(context.Background(), 9, false, false)
+
+	assert.False(s.T(), it.Next())
+	assert.NoError(s.T(), it.Err())
+	assert.Len(s.T(), s.requests, 1)
+}
+
+func (s *iteratorTestSuite) TestIterateBlocksError() {
+	s.ts.Close()
+
+	it := NewDaemonClient(s.ts.URL, "", "", WithRetryPolicy(RetryPolicy{MaxAttempts: 1})).IterateBlocks(context.Background(), 1, false, false)
+
+	assert.False(s.T(), it.Next())
+	assert.Error(s.T(), it.Err())
+}
+
+func (s *iteratorTestSuite) TestIterateHashes() {
+	it := NewDaemonClient(s.ts.URL, "", "").IterateHashes(context.Background(), 0)
+
+	var heights []uint
+	for it.Next() {
+		assert.Equal(s.T(), s.hash(it.Height()), it.Hash())
+		heights = append(heights, it.Height())
+	}
+
+	assert.NoError(s.T(), it.Err())
+	assert.Equal(s.T(), []uint{0, 1, 2, 3, 4, 5, 6}, heights)
+	assert.Len(s.T(), s.requests, 3)
+
+	genesis := s.hash(0)
+	last := s.hash(2)
+	assert.Equal(s.T(), genesis[:], s.requests[0]["block_ids"])
+	assert.Equal(s.T(), append(last[:], genesis[:]...), s.requests[1]["block_ids"])
+	assert.Equal(s.T(), uint64(3), s.requests[1]["start_height"])
+}
+
+func (s *iteratorTestSuite) TestIterateHashesFromHeight() {
+	it := NewDaemonClient(s.ts.URL, "", "").IterateHashes(context.Background(), 5)
+
+	var heights []uint
+	for it.Next() {
+		assert.Equal(s.T(), s.hash(it.Height()), it.Hash())
+		heights = append(heights, it.Height())
+	}
+
+	assert.NoError(s.T(), it.Err())
+	assert.Equal(s.T(), []uint{5, 6}, heights)
+	assert.Len(s.T(), s.requests, 1)
+
+	genesis := s.hash(0)
+	assert.Equal(s.T(), append([]byte{4}, make([]byte, 31)...), s.requests[0]["block_ids"].([]byte)[:32])
+	assert.Equal(s.T(), genesis[:], s.requests[0]["block_ids"].([]byte)[32:])
+}
+
+func (s *iteratorTestSuite) TestIterateHashesEmpty() {
+	it := NewDaemonClient(s.ts.URL, "", "").IterateHashes(context.Background(), 7)
+
+	assert.False(s.T(), it.Next())
+	assert.NoError(s.T(), it.Err())
+}
@@ -0,0 +1,240 @@
+package xmrrpc
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type ThrottleStats struct {
+	Throttled uint64
+	WaitTime  time.Duration
+}
+
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if !(rate > 0) {
+		return nil
+	}
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &rateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+func (l *rateLimiter) wait(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return false, nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return true, ctx.Err()
+	case <-timer.C:
+		return true, nil
+	}
+}
+
+type semaphore chan struct{}
+
+func newSemaphore(n int) semaphore {
+	if n <= 0 {
+		return nil
+	}
+
+	return make(semaphore, n)
+}
+
+func (s semaphore) acquire(ctx context.Context) (bool, error) {
+	select {
+	case s <- struct{}{}:
+		return false, nil
+	default:
+	}
+
+	select {
+	case s <- struct{}{}:
+		return true, nil
+	case <-ctx.Done():
+		return true, ctx.Err()
+	}
+}
+
+func (s semaphore) release() {
+	<-s
+}
+
+type limiter struct {
+	rate     *rateLimiter
+	inFlight semaphore
+}
+
+type limits struct {
+	global    limiter
+	methods   map[string]*limiter
+	throttled uint64
+	waitTime  int64
+}
+
+func (l *limits) method(method string) *limiter {
+	if l.methods == nil {
+		l.methods = map[string]*limiter{}
+	}
+
+	if l.methods[method] == nil {
+		l.methods[method] = &limiter{}
+	}
+
+	return l.methods[method]
+}
+
+// acquire waits for a rate token per method, so a batch costs as many tokens
+// as it has calls, and for one in-flight slot of every limiter involved.
+func (l *limits) acquire(ctx context.Context, methods ...string) (func(), error) {
+	var rates []*rateLimiter
+	names := map[string]bool{}
+	for _, method := range methods {
+		if m, ok := l.methods[method]; ok {
+			if m.rate != nil {
+				rates = append(rates, m.rate)
+			}
+			names[method] = true
+		}
+	}
+
+	// Slots are taken in a fixed order, batches with the same methods in a
+	// different order would deadlock otherwise.
+	var slots []semaphore
+	for _, method := range sortedKeys(names) {
+		if m := l.methods[method]; m.inFlight != nil {
+			slots = append(slots, m.inFlight)
+		}
+	}
+	if l.global.rate != nil {
+		for range methods {
+			rates = append(rates, l.global.rate)
+		}
+	}
+	if l.global.inFlight != nil {
+		slots = append(slots, l.global.inFlight)
+	}
+
+	start := time.Now()
+	throttled := false
+	var acquired []semaphore
+	release := func() {
+		for _, s := range acquired {
+			s.release()
+		}
+	}
+	done := func(err error) (func(), error) {
+		if throttled {
+			atomic.AddUint64(&l.throttled, 1)
+			atomic.AddInt64(&l.waitTime, int64(time.Since(start)))
+		}
+
+		if err != nil {
+			release()
+			return nil, err
+		}
+
+		return release, nil
+	}
+
+	for _, rate := range rates {
+		waited, err := rate.wait(ctx)
+		throttled = throttled || waited
+		if err != nil {
+			return done(err)
+		}
+	}
+
+	for _, slot := range slots {
+		waited, err := slot.acquire(ctx)
+		throttled = throttled || waited
+		if err != nil {
+			return done(err)
+		}
+		acquired = append(acquired, slot)
+	}
+
+	return done(nil)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (l *limits) stats() ThrottleStats {
+	return ThrottleStats{
+		Throttled: atomic.LoadUint64(&l.throttled),
+		WaitTime:  time.Duration(atomic.LoadInt64(&l.waitTime)),
+	}
+}
+
+// WithRateLimit allows rate requests per second with bursts of burst requests,
+// a rate of 0 or less means no limit and a burst below 1 is raised to 1.
+func WithRateLimit(rate float64, burst int) Option {
+	return func(dc *DaemonClient) {
+		dc.limits.global.rate = newRateLimiter(rate, burst)
+	}
+}
+
+// WithMaxInFlight caps concurrent requests at n, 0 or less means no limit.
+func WithMaxInFlight(n int) Option {
+	return func(dc *DaemonClient) {
+		dc.limits.global.inFlight = newSemaphore(n)
+	}
+}
+
+// WithMethodRateLimit is WithRateLimit for a single method.
+func WithMethodRateLimit(method string, rate float64, burst int) Option {
+	return func(dc *DaemonClient) {
+		dc.limits.method(method).rate = newRateLimiter(rate, burst)
+	}
+}
+
+// WithMethodMaxInFlight is WithMaxInFlight for a single method.
+func WithMethodMaxInFlight(method string, n int) Option {
+	return func(dc *DaemonClient) {
+		dc.limits.method(method).inFlight = newSemaphore(n)
+	}
+}
+
+func (dc *DaemonClient) ThrottleStats() ThrottleStats {
+	return dc.limits.stats()
+}
@@ -0,0 +1,184 @@
+package xmrrpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type limitTestSuite struct {
+	suite.Suite
+	ts      *httptest.Server
+	current int32
+	max     int32
+	delay   time.Duration
+}
+
+func TestLimitTestSuite(t *testing.T) {
+	suite.Run(t, new(limitTestSuite))
+}
+
+func (s *limitTestSuite) SetupTest() {
+	s.current, s.max, s.delay = 0, 0, 0
+	s.ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&s.current, 1)
+		defer atomic.AddInt32(&s.current, -1)
+
+		for {
+			max := atomic.LoadInt32(&s.max)
+			if n <= max || atomic.CompareAndSwapInt32(&s.max, max, n) {
+				break
+			}
+		}
+
+		time.Sleep(s.delay)
+		w.Write([]byte(`{"status":"OK"}`))
+	}))
+}
+
+func (s *limitTestSuite) TearDownTest() {
+	s.ts.Close()
+}
+
+func (s *limitTestSuite) parallel(n int, f func()) {
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f()
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *limitTestSuite) TestRateLimit() {
+	dc := NewDaemonClient(s.ts.URL, "username", "password", WithRateLimit(20, 2))
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		_, err := dc.GetHeight()
+		assert.NoError(s.T(), err)
+	}
+
+	assert.True(s.T(), time.Since(start) >= 150*time.Millisecond, time.Since(start))
+
+	stats := dc.ThrottleStats()
+	assert.True(s.T(), stats.Throttled >= 3, stats.Throttled)
+	assert.True(s.T(), stats.WaitTime >= 150*time.Millisecond, stats.WaitTime)
+}
+
+func (s *limitTestSuite) TestRateLimitContext() {
+	dc := NewDaemonClient(s.ts.URL, "username", "password", WithRateLimit(0.1, 1))
+
+	_, err := dc.GetHeight()
+	assert.NoError(s.T(), err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = dc.GetHeightContext(ctx)
+	assert.Equal(s.T(), context.DeadlineExceeded, err)
+	assert.True(s.T(), time.Since(start) < time.Second)
+	assert.Equal(s.T(), uint64(1), dc.ThrottleStats().Throttled)
+}
+
+func (s *limitTestSuite) TestMethodRateLimit() {
+	dc := NewDaemonClient(s.ts.URL, "username", "password", WithMethodRateLimit("get_transactions", 0.1, 1))
+
+	_, err := dc.GetTransactions(nil, false, false)
+	assert.NoError(s.T(), err)
+
+	for i := 0; i < 5; i++ {
+		_, err := dc.GetHeight()
+		assert.NoError(s.T(), err)
+	}
+	assert.Zero(s.T(), dc.ThrottleStats().Throttled)
+}
+
+func (s *limitTestSuite) TestMaxInFlight() {
+	s.delay = 20 * time.Millisecond
+	dc := NewDaemonClient(s.ts.URL, "username", "password", WithMaxInFlight(2))
+
+	s.parallel(6, func() {
+		_, err := dc.GetHeight()
+		assert.NoError(s.T(), err)
+	})
+
+	assert.Equal(s.T(), int32(2), s.max)
+	assert.True(s.T(), dc.ThrottleStats().Throttled > 0)
+}
+
+func (s *limitTestSuite) TestMethodMaxInFlight() {
+	s.delay = 20 * time.Millisecond
+	dc := NewDaemonClient(s.ts.URL, "username", "password", WithMethodMaxInFlight("get_block", 1))
+
+	s.parallel(4, func() {
+		_, err := dc.GetBlock(1, "")
+		assert.Error(s.T(), err, "Response of fake server is not JSON RPC.")
+	})
+	assert.Equal(s.T(), int32(1), s.max)
+
+	s.parallel(4, func() {
+		_, err := dc.GetHeight()
+		assert.NoError(s.T(), err)
+	})
+	assert.True(s.T(), s.max > 1)
+}
+
+func (s *limitTestSuite) TestBatchRateLimit() {
+	for _, opt := range []Option{WithMethodRateLimit("get_block", 20, 2), WithRateLimit(20, 2)} {
+		b := NewDaemonClient(s.ts.URL, "username", "password", opt).NewBatch()
+		for i := 0; i < 6; i++ {
+			b.Add("get_block", map[string]int{"height": i}, new(BlockResponse))
+		}
+
+		// Every call of the batch takes a token, 6 calls need 200ms.
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		err := b.SendContext(ctx)
+		cancel()
+
+		assert.Equal(s.T(), context.DeadlineExceeded, err)
+		assert.Zero(s.T(), atomic.LoadInt32(&s.max), "Batch must not be sent before the tokens are acquired.")
+	}
+}
+
+func (s *limitTestSuite) TestDisabledLimits() {
+	dc := NewDaemonClient(s.ts.URL, "username", "password",
+		WithRateLimit(0, 1),
+		WithMethodRateLimit("get_height", -1, 1),
+		WithMaxInFlight(0),
+		WithMethodMaxInFlight("get_height", -1),
+	)
+	assert.Nil(s.T(), dc.limits.global.rate)
+	assert.Nil(s.T(), dc.limits.global.inFlight)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	s.parallel(4, func() {
+		for i := 0; i < 5; i++ {
+			_, err := dc.GetHeightContext(ctx)
+			assert.NoError(s.T(), err)
+		}
+	})
+	assert.Zero(s.T(), dc.ThrottleStats().Throttled)
+}
+
+func (s *limitTestSuite) TestRateLimitBurst() {
+	dc := NewDaemonClient(s.ts.URL, "username", "password", WithRateLimit(20, 0))
+
+	for i := 0; i < 2; i++ {
+		_, err := dc.GetHeight()
+		assert.NoError(s.T(), err)
+	}
+	assert.Equal(s.T(), uint64(1), dc.ThrottleStats().Throttled, "A burst below 1 must allow one request at a time.")
+}
@@ -0,0 +1,144 @@
+package xmrrpc
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+var DefaultLogLevels = LogLevels{Success: slog.LevelDebug, Failure: slog.LevelWarn}
+
+// Params and headers with these names are never logged.
+var redactedParams = map[string]bool{
+	"txashex":        true,
+	"tx_as_hex":      true,
+	"miner_address":  true,
+	"wallet_address": true,
+	"username":       true,
+	"password":       true,
+	"login":          true,
+}
+
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+}
+
+type LogLevels struct {
+	Success slog.Level
+	Failure slog.Level
+}
+
+type exchange struct {
+	header http.Header
+	status int
+	size   int
+}
+
+func WithLogger(logger *slog.Logger) Option {
+	return func(dc *DaemonClient) {
+		dc.logger = logger
+	}
+}
+
+func WithLogLevels(success slog.Level, failure slog.Level) Option {
+	return func(dc *DaemonClient) {
+		dc.logLevels = LogLevels{Success: success, Failure: failure}
+	}
+}
+
+func (dc *DaemonClient) logExchange(ctx context.Context, inv *Invocation, ex exchange, duration time.Duration, err error) {
+	level := dc.logLevels.Success
+	if err != nil {
+		level = dc.logLevels.Failure
+	}
+
+	if !dc.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", inv.Method),
+		slog.String("endpoint", inv.Endpoint),
+		slog.String("url", redactURL(dc.endpoint)),
+		slog.Duration("duration", duration),
+		slog.Int("status", ex.status),
+		slog.Int("size", ex.size),
+		slog.Any("params", redactedValue{inv.Params}),
+	}
+	if len(ex.header) > 0 {
+		attrs = append(attrs, slog.Any("header", redactHeader(ex.header)))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	dc.logger.LogAttrs(ctx, level, "rpc call", attrs...)
+}
+
+type redactedValue struct {
+	v interface{}
+}
+
+func (r redactedValue) LogValue() slog.Value {
+	raw, err := json.Marshal(r.v)
+	if err != nil {
+		return slog.StringValue(redacted)
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return slog.StringValue(redacted)
+	}
+
+	raw, _ = json.Marshal(redact(v))
+	return slog.StringValue(string(raw))
+}
+
+func redact(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			if redactedParams[strings.ToLower(k)] {
+				v[k] = redacted
+			} else {
+				v[k] = redact(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redact(item)
+		}
+	}
+
+	return v
+}
+
+func redactHeader(header http.Header) http.Header {
+	result := make(http.Header, len(header))
+	for k, v := range header {
+		if redactedHeaders[http.CanonicalHeaderKey(k)] {
+			v = []string{redacted}
+		}
+		result[k] = v
+	}
+
+	return result
+}
+
+func redactURL(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.User == nil {
+		return endpoint
+	}
+
+	u.User = nil
+	return u.String()
+}
@@ -0,0 +1,60 @@
+package xmrrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"time"
+)
+
+// Metrics receives instrumentation events of a DaemonClient, it must be safe for concurrent use.
+type Metrics interface {
+	// InFlight is called with +1 when a call starts and -1 when it ends.
+	InFlight(method string, delta int)
+	// Observe is called once per call, after all the retries, err is classified with ErrorClass.
+	Observe(method string, duration time.Duration, err error)
+	Retry(method string)
+	Reauth()
+}
+
+func WithMetrics(metrics Metrics) Option {
+	return func(dc *DaemonClient) {
+		dc.metrics = metrics
+	}
+}
+
+// ErrorClass returns a short label for the kind of err, suitable as a metric label.
+func ErrorClass(err error) string {
+	var rpcErr *RPCError
+	var statusErr *StatusError
+	var httpErr *HTTPError
+	var netErr net.Error
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrCircuitOpen):
+		return "circuit_open"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.As(err, &rpcErr):
+		return "rpc"
+	case errors.As(err, &statusErr):
+		return "status"
+	case errors.As(err, &httpErr):
+		return "http"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.As(err, &netErr):
+		return "network"
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return "decode"
+	}
+
+	return "other"
+}
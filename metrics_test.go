@@ -0,0 +1,151 @@
+package xmrrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type testMetrics struct {
+	mu       sync.Mutex
+	inFlight map[string]int
+	maxIn    int
+	observed map[string][]string
+	retries  map[string]int
+	reauths  int
+}
+
+func newTestMetrics() *testMetrics {
+	return &testMetrics{inFlight: map[string]int{}, observed: map[string][]string{}, retries: map[string]int{}}
+}
+
+func (m *testMetrics) InFlight(method string, delta int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.inFlight[method] += delta
+	if m.inFlight[method] > m.maxIn {
+		m.maxIn = m.inFlight[method]
+	}
+}
+
+func (m *testMetrics) Observe(method string, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.observed[method] = append(m.observed[method], ErrorClass(err))
+}
+
+func (m *testMetrics) Retry(method string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.retries[method]++
+}
+
+func (m *testMetrics) Reauth() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reauths++
+}
+
+type metricsTestSuite struct {
+	suite.Suite
+	ts    *httptest.Server
+	fails int32
+}
+
+func TestMetricsTestSuite(t *testing.T) {
+	suite.Run(t, new(metricsTestSuite))
+}
+
+func (s *metricsTestSuite) SetupTest() {
+	s.fails = 0
+	s.ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.Header().Add("WWW-authenticate", `Digest qop="auth",algorithm=MD5,realm="monero-rpc",nonce="nonce",stale=false`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if atomic.AddInt32(&s.fails, -1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var req jsonRPCRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Method == "get_block_header_by_height" {
+			w.Write([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"error":{"code":-2,"message":"Too big height"}}`, req.ID)))
+			return
+		}
+
+		w.Write([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"status":"OK","count":1}}`, req.ID)))
+	}))
+}
+
+func (s *metricsTestSuite) TearDownTest() {
+	s.ts.Close()
+}
+
+func (s *metricsTestSuite) TestCalls() {
+	m := newTestMetrics()
+	dc := NewDaemonClient(s.ts.URL, "username", "password", WithMetrics(m))
+
+	_, err := dc.GetBlockCount()
+	assert.NoError(s.T(), err)
+	_, err = dc.GetBlockCount()
+	assert.NoError(s.T(), err)
+	_, err = dc.GetBlockHeaderByHeight(1 << 30)
+	assert.Error(s.T(), err)
+
+	assert.Equal(s.T(), []string{"", ""}, m.observed["get_block_count"])
+	assert.Equal(s.T(), []string{"rpc"}, m.observed["get_block_header_by_height"])
+	assert.Equal(s.T(), 1, m.reauths, "Session must be reused.")
+	assert.Equal(s.T(), 1, m.maxIn)
+	assert.Zero(s.T(), m.inFlight["get_block_count"])
+}
+
+func (s *metricsTestSuite) TestRetries() {
+	m := newTestMetrics()
+	policy := DefaultRetryPolicy
+	policy.InitialBackoff = time.Millisecond
+	dc := NewDaemonClient(s.ts.URL, "username", "password", WithMetrics(m), WithRetryPolicy(policy))
+
+	s.fails = 2
+	_, err := dc.GetBlockCount()
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, m.retries["get_block_count"])
+	assert.Equal(s.T(), []string{""}, m.observed["get_block_count"])
+
+	s.fails = 3
+	_, err = dc.GetBlockCount()
+	assert.Error(s.T(), err)
+	assert.Equal(s.T(), 4, m.retries["get_block_count"])
+	assert.Equal(s.T(), []string{"", "http"}, m.observed["get_block_count"])
+}
+
+func (s *metricsTestSuite) TestErrorClass() {
+	assert.Equal(s.T(), "", ErrorClass(nil))
+	assert.Equal(s.T(), "rpc", ErrorClass(ErrCoreBusy))
+	assert.Equal(s.T(), "status", ErrorClass(&StatusError{Status: "BUSY"}))
+	assert.Equal(s.T(), "http", ErrorClass(&HTTPError{StatusCode: 502}))
+	assert.Equal(s.T(), "circuit_open", ErrorClass(ErrCircuitOpen))
+	assert.Equal(s.T(), "canceled", ErrorClass(fmt.Errorf("call: %w", context.Canceled)))
+	assert.Equal(s.T(), "timeout", ErrorClass(context.DeadlineExceeded))
+	assert.Equal(s.T(), "network", ErrorClass(&net.OpError{Op: "dial", Err: errors.New("connection refused")}))
+	assert.Equal(s.T(), "decode", ErrorClass(json.Unmarshal([]byte("{"), &struct{}{})))
+	assert.Equal(s.T(), "other", ErrorClass(errors.New("Unexpected null result")))
+}
@@ -0,0 +1,72 @@
+package xmrrpc
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+var ErrUnsupportedTransport = errors.New("TLS and proxy options require an *http.Transport")
+
+type Option func(dc *DaemonClient)
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(dc *DaemonClient) {
+		if client != nil {
+			dc.client = client
+		}
+	}
+}
+
+func WithTimeout(timeout time.Duration) Option {
+	return func(dc *DaemonClient) {
+		dc.timeout = timeout
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(dc *DaemonClient) {
+		dc.header.Set("User-Agent", userAgent)
+	}
+}
+
+func WithHeaders(header http.Header) Option {
+	return func(dc *DaemonClient) {
+		for k, v := range header {
+			dc.header[http.CanonicalHeaderKey(k)] = append([]string(nil), v...)
+		}
+	}
+}
+
+func WithoutStatusCheck() Option {
+	return func(dc *DaemonClient) {
+		dc.noStatus = true
+	}
+}
+
+// transport returns a copy of rt with the TLS and proxy configuration applied.
+// It fails for a custom http.RoundTripper, which cannot be configured.
+func (dc *DaemonClient) transport(rt http.RoundTripper) (http.RoundTripper, bool) {
+	if dc.tlsConfig == nil && dc.proxy == nil {
+		return rt, true
+	}
+
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		return rt, false
+	}
+
+	t = t.Clone()
+	if dc.tlsConfig != nil {
+		t.TLSClientConfig = dc.tlsConfig
+	}
+	if dc.proxy != nil {
+		t.Proxy = http.ProxyURL(dc.proxy)
+	}
+
+	return t, true
+}
@@ -0,0 +1,80 @@
+package xmrrpc
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type optionsTestSuite struct {
+	suite.Suite
+}
+
+func TestOptionsTestSuite(t *testing.T) {
+	suite.Run(t, new(optionsTestSuite))
+}
+
+func (s *optionsTestSuite) TestDefaults() {
+	dc := NewDaemonClient("http://127.0.0.1:38081", "username", "password")
+	assert.Equal(s.T(), http.DefaultClient, dc.client)
+	assert.Empty(s.T(), dc.header)
+}
+
+func (s *optionsTestSuite) TestWithHTTPClient() {
+	client := &http.Client{}
+	dc := NewDaemonClient("http://127.0.0.1:38081", "username", "password", WithHTTPClient(client))
+	assert.Equal(s.T(), client, dc.client)
+
+	dc = NewDaemonClient("http://127.0.0.1:38081", "username", "password", WithHTTPClient(nil))
+	assert.Equal(s.T(), http.DefaultClient, dc.client)
+}
+
+func (s *optionsTestSuite) TestWithTimeout() {
+	client := &http.Client{Transport: &http.Transport{}}
+	dc := NewDaemonClient("http://127.0.0.1:38081", "username", "password", WithTimeout(time.Second), WithHTTPClient(client))
+	if assert.NotEqual(s.T(), client, dc.client, "Shared client must not be modified.") {
+		assert.Equal(s.T(), time.Second, dc.client.Timeout)
+		assert.Equal(s.T(), client.Transport, dc.client.Transport)
+		assert.Zero(s.T(), client.Timeout)
+	}
+
+	assert.Zero(s.T(), http.DefaultClient.Timeout)
+}
+
+func (s *optionsTestSuite) TestWithTimeoutExpired() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	_, err := NewDaemonClient(ts.URL, "username", "password", WithTimeout(50*time.Millisecond)).GetHeight()
+	assert.Error(s.T(), err)
+}
+
+func (s *optionsTestSuite) TestWithHeaders() {
+	var header http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		w.Write([]byte(`{"status":"OK"}`))
+	}))
+	defer ts.Close()
+
+	dc := NewDaemonClient(ts.URL, "username", "password",
+		WithHeaders(http.Header{"x-request-source": {"test"}, "Content-Type": {"text/plain"}}),
+		WithUserAgent("xmrrpc-test/1.0"),
+	)
+
+	res, err := dc.GetHeight()
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), "OK", res.Status)
+		assert.Equal(s.T(), "test", header.Get("X-Request-Source"))
+		assert.Equal(s.T(), "xmrrpc-test/1.0", header.Get("User-Agent"))
+		assert.Equal(s.T(), "application/json", header.Get("Content-Type"))
+	}
+}
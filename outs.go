@@ -0,0 +1,73 @@
+package xmrrpc
+
+import (
+	"context"
+)
+
+// MaxOutsPerRequest is the number of outputs a restricted daemon returns per
+// get_outs call, larger lists are split into several calls.
+const MaxOutsPerRequest = 5000
+
+type OutputRequest struct {
+	Amount uint `json:"amount" epee:"amount"`
+	Index  uint `json:"index" epee:"index"`
+}
+
+type OutputEntry struct {
+	Key      Hash `json:"key" epee:"key"`
+	Mask     Hash `json:"mask" epee:"mask"`
+	Unlocked bool `json:"unlocked" epee:"unlocked"`
+	Height   uint `json:"height" epee:"height"`
+	TxID     Hash `json:"txid" epee:"txid"`
+}
+
+type OutsResponse struct {
+	Outs      []OutputEntry `json:"outs" epee:"outs"`
+	Credits   uint          `json:"credits" epee:"credits"`
+	Status    string        `json:"status" epee:"status"`
+	TopHash   string        `json:"top_hash" epee:"top_hash"`
+	Untrusted bool          `json:"untrusted" epee:"untrusted"`
+}
+
+func (dc *DaemonClient) GetOuts(outputs []OutputRequest, getTxID bool) (response OutsResponse, err error) {
+	return dc.GetOutsContext(context.Background(), outputs, getTxID)
+}
+
+func (dc *DaemonClient) GetOutsContext(ctx context.Context, outputs []OutputRequest, getTxID bool) (response OutsResponse, err error) {
+	return dc.getOuts(ctx, "/get_outs", outputs, getTxID)
+}
+
+func (dc *DaemonClient) GetOutsBin(outputs []OutputRequest, getTxID bool) (response OutsResponse, err error) {
+	return dc.GetOutsBinContext(context.Background(), outputs, getTxID)
+}
+
+func (dc *DaemonClient) GetOutsBinContext(ctx context.Context, outputs []OutputRequest, getTxID bool) (response OutsResponse, err error) {
+	return dc.getOuts(ctx, "/get_outs.bin", outputs, getTxID)
+}
+
+// getOuts requests outputs in batches of MaxOutsPerRequest, the outs fetched
+// before a failed batch are returned along with the error.
+func (dc *DaemonClient) getOuts(ctx context.Context, path string, outputs []OutputRequest, getTxID bool) (response OutsResponse, err error) {
+	type Params struct {
+		Outputs []OutputRequest `json:"outputs" epee:"outputs"`
+		GetTxID bool            `json:"get_txid" epee:"get_txid"`
+	}
+
+	for start := 0; start < len(outputs); start += MaxOutsPerRequest {
+		end := start + MaxOutsPerRequest
+		if end > len(outputs) {
+			end = len(outputs)
+		}
+
+		var part OutsResponse
+		err = dc.rpcRequest(ctx, path, Params{Outputs: outputs[start:end], GetTxID: getTxID}, &part)
+		response.Outs = append(response.Outs, part.Outs...)
+		response.Credits, response.Status, response.TopHash = part.Credits, part.Status, part.TopHash
+		response.Untrusted = response.Untrusted || part.Untrusted
+		if err != nil {
+			return response, err
+		}
+	}
+
+	return response, nil
+}
@@ -0,0 +1,118 @@
+package xmrrpc
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stdfox/xmrrpc/epee"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type outsTestSuite struct {
+	suite.Suite
+	ts       *httptest.Server
+	requests []int
+	status   string
+}
+
+func (s *outsTestSuite) SetupTest() {
+	s.requests, s.status = nil, "OK"
+	s.ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Outputs []OutputRequest `json:"outputs" epee:"outputs"`
+			GetTxID bool            `json:"get_txid" epee:"get_txid"`
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		binary := r.URL.Path == "/get_outs.bin"
+		if binary {
+			epee.Unmarshal(body, &req)
+		} else {
+			json.Unmarshal(body, &req)
+		}
+		s.requests = append(s.requests, len(req.Outputs))
+
+		res := OutsResponse{Status: s.status, Credits: uint(len(s.requests))}
+		for _, out := range req.Outputs {
+			entry := OutputEntry{Key: Hash{byte(out.Index), byte(out.Index >> 8)}, Mask: Hash{0xff}, Unlocked: true, Height: out.Index}
+			if req.GetTxID {
+				entry.TxID = Hash{1}
+			}
+			res.Outs = append(res.Outs, entry)
+		}
+
+		if binary {
+			data, _ := epee.Marshal(res)
+			w.Write(data)
+			return
+		}
+
+		json.NewEncoder(w).Encode(res)
+	}))
+}
+
+func (s *outsTestSuite) TearDownTest() {
+	s.ts.Close()
+}
+
+func TestOutsTestSuite(t *testing.T) {
+	suite.Run(t, new(outsTestSuite))
+}
+
+func (s *outsTestSuite) TestGetOuts() {
+	res, err := NewDaemonClient(s.ts.URL, "", "").GetOuts([]OutputRequest{{Index: 3}, {Index: 260}}, false)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []OutputEntry{
+		{Key: Hash{3}, Mask: Hash{0xff}, Unlocked: true, Height: 3},
+		{Key: Hash{4, 1}, Mask: Hash{0xff}, Unlocked: true, Height: 260},
+	}, res.Outs)
+	assert.Equal(s.T(), "OK", res.Status)
+}
+
+func (s *outsTestSuite) TestGetOutsEmptyTxID() {
+	var entry OutputEntry
+	err := json.Unmarshal([]byte(`{"key":"418015bb9ae982a1975da7d79277c2705727a56894ba0fb246adaabb1f4632e3","mask":"","txid":"","height":1}`), &entry)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), Hash{}, entry.TxID)
+	assert.Equal(s.T(), "418015bb9ae982a1975da7d79277c2705727a56894ba0fb246adaabb1f4632e3", entry.Key.String())
+}
+
+func (s *outsTestSuite) TestGetOutsBin() {
+	res, err := NewDaemonClient(s.ts.URL, "", "").GetOutsBin([]OutputRequest{{Index: 3}}, true)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []OutputEntry{{Key: Hash{3}, Mask: Hash{0xff}, Unlocked: true, Height: 3, TxID: Hash{1}}}, res.Outs)
+}
+
+func (s *outsTestSuite) TestGetOutsBatches() {
+	outputs := make([]OutputRequest, 2*MaxOutsPerRequest+1)
+	for i := range outputs {
+		outputs[i].Index = uint(i)
+	}
+
+	res, err := NewDaemonClient(s.ts.URL, "", "").GetOutsBin(outputs, false)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []int{MaxOutsPerRequest, MaxOutsPerRequest, 1}, s.requests)
+	assert.Len(s.T(), res.Outs, len(outputs))
+	assert.Equal(s.T(), uint(2*MaxOutsPerRequest), res.Outs[2*MaxOutsPerRequest].Height)
+	assert.Equal(s.T(), uint(3), res.Credits)
+}
+
+func (s *outsTestSuite) TestGetOutsBatchError() {
+	s.status = "Failed"
+
+	res, err := NewDaemonClient(s.ts.URL, "", "").GetOuts(make([]OutputRequest, MaxOutsPerRequest+1), false)
+	assert.ErrorIs(s.T(), err, ErrStatusFailed)
+	assert.Equal(s.T(), []int{MaxOutsPerRequest}, s.requests)
+	assert.Len(s.T(), res.Outs, MaxOutsPerRequest)
+}
+
+func (s *outsTestSuite) TestGetOutsEmpty() {
+	res, err := NewDaemonClient(s.ts.URL, "", "").GetOuts(nil, false)
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), res.Outs)
+	assert.Empty(s.T(), s.requests)
+}
@@ -0,0 +1,248 @@
+package xmrrpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"filippo.io/edwards25519"
+	"github.com/stdfox/xmrrpc/epee"
+	"golang.org/x/crypto/sha3"
+)
+
+var ErrPaymentDisabled = errors.New("RPC payment is not enabled")
+
+// Miner searches the hashing blob of info for nonces meeting info.Diff and
+// returns them, the proof of work (RandomX) is left to the caller.
+type Miner func(ctx context.Context, info RPCAccessInfoResponse) ([]uint, error)
+
+type payment struct {
+	secret *edwards25519.Scalar
+	public []byte
+	now    func() time.Time
+
+	mu        sync.Mutex
+	timestamp int64
+	credits   uint
+	topHash   string
+}
+
+type accessFields struct {
+	Credits *uint  `json:"credits" epee:"credits"`
+	TopHash string `json:"top_hash" epee:"top_hash"`
+}
+
+// WithRPCPayment signs every call with the client parameter of nodes running
+// with --rpc-payment-address, a random key is used when secretKey is not 32 bytes long.
+func WithRPCPayment(secretKey []byte) Option {
+	return func(dc *DaemonClient) {
+		if len(secretKey) != 32 {
+			secretKey = make([]byte, 32)
+			if _, err := rand.Read(secretKey); err != nil {
+				panic(err)
+			}
+		}
+
+		dc.payment = newPayment(secretKey)
+	}
+}
+
+func newPayment(secretKey []byte) *payment {
+	secret := scalarReduce(secretKey)
+	public := new(edwards25519.Point).ScalarBaseMult(secret).Bytes()
+
+	return &payment{secret: secret, public: public, now: time.Now}
+}
+
+func scalarReduce(b []byte) *edwards25519.Scalar {
+	wide := make([]byte, 64)
+	copy(wide, b)
+
+	s, err := edwards25519.NewScalar().SetUniformBytes(wide)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+func keccak(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+
+	return h.Sum(nil)
+}
+
+// signature returns the client parameter: the public key, the timestamp in
+// microseconds and a signature of its hash, as make_rpc_payment_signature of
+// monerod. The node rejects a timestamp not greater than the previous one.
+func (p *payment) signature() string {
+	p.mu.Lock()
+	p.timestamp++
+	if now := p.now().UnixMicro(); now > p.timestamp {
+		p.timestamp = now
+	}
+	ts := fmt.Sprintf("%016x", p.timestamp)
+	p.mu.Unlock()
+
+	hash := keccak([]byte(ts))
+
+	var c, r *edwards25519.Scalar
+	for {
+		random := make([]byte, 64)
+		if _, err := rand.Read(random); err != nil {
+			panic(err)
+		}
+
+		k, err := edwards25519.NewScalar().SetUniformBytes(random)
+		if err != nil {
+			panic(err)
+		}
+
+		comm := new(edwards25519.Point).ScalarBaseMult(k).Bytes()
+		c = scalarReduce(keccak(hash, p.public, comm))
+		if c.Equal(edwards25519.NewScalar()) == 1 {
+			continue
+		}
+
+		r = edwards25519.NewScalar().Subtract(k, edwards25519.NewScalar().Multiply(c, p.secret))
+		break
+	}
+
+	return hex.EncodeToString(p.public) + ts + hex.EncodeToString(c.Bytes()) + hex.EncodeToString(r.Bytes())
+}
+
+// sign returns params with the client parameter set, params which are not an
+// object or already carry a client are returned as is.
+func (p *payment) sign(endpoint string, params interface{}) (interface{}, error) {
+	if params == nil {
+		return map[string]interface{}{"client": p.signature()}, nil
+	}
+
+	var values map[string]interface{}
+	if strings.HasSuffix(endpoint, ".bin") {
+		data, err := epee.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		if err := epee.Unmarshal(data, &values); err != nil {
+			return nil, err
+		}
+		if client, ok := values["client"].([]byte); ok && len(client) > 0 {
+			return params, nil
+		}
+	} else {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.HasPrefix(raw, []byte("{")) {
+			return params, nil
+		}
+
+		d := json.NewDecoder(bytes.NewReader(raw))
+		d.UseNumber()
+		if err := d.Decode(&values); err != nil {
+			return nil, err
+		}
+		if client, ok := values["client"].(string); ok && client != "" {
+			return params, nil
+		}
+	}
+
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	values["client"] = p.signature()
+
+	return values, nil
+}
+
+// trackJSON records the credits and top hash of a JSON response or result.
+func (p *payment) trackJSON(data []byte) {
+	var fields accessFields
+	if json.Unmarshal(data, &fields) == nil {
+		p.track(fields)
+	}
+}
+
+// trackEpee records the credits and top hash of a binary response.
+func (p *payment) trackEpee(data []byte) {
+	var fields accessFields
+	if epee.Unmarshal(data, &fields) == nil {
+		p.track(fields)
+	}
+}
+
+func (p *payment) track(fields accessFields) {
+	if fields.Credits == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.credits = *fields.Credits
+	if fields.TopHash != "" {
+		p.topHash = fields.TopHash
+	}
+}
+
+// Credits returns the credit balance and top hash last reported by the node.
+func (dc *DaemonClient) Credits() (credits uint, topHash string) {
+	if dc.payment == nil {
+		return 0, ""
+	}
+
+	dc.payment.mu.Lock()
+	defer dc.payment.mu.Unlock()
+
+	return dc.payment.credits, dc.payment.topHash
+}
+
+// MineCredits earns credits by submitting the nonces found by miner until the
+// balance reaches target, or forever when target is 0, or ctx is done.
+func (dc *DaemonClient) MineCredits(ctx context.Context, miner Miner, target uint) error {
+	if dc.payment == nil {
+		return ErrPaymentDisabled
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		info, err := dc.RPCAccessInfoContext(ctx, "")
+		if err != nil {
+			return err
+		}
+
+		if target > 0 && info.Credits >= target {
+			return nil
+		}
+
+		nonces, err := miner(ctx, info)
+		if err != nil {
+			return err
+		}
+
+		for _, nonce := range nonces {
+			_, err := dc.RPCAccessSubmitNonceContext(ctx, "", nonce, info.Cookie)
+			if errors.Is(err, ErrStalePayment) {
+				break
+			}
+			if err != nil && !errors.Is(err, ErrDuplicatePayment) {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package xmrrpc
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"filippo.io/edwards25519"
+	"github.com/stdfox/xmrrpc/epee"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	paidCallCost = 10
+	nonceCredits = 25
+)
+
+// paidDaemon is a fake node with --rpc-payment-address: every call costs
+// credits, earned by submitting even nonces for the current cookie.
+type paidDaemon struct {
+	mu         sync.Mutex
+	credits    map[string]uint
+	timestamps map[string]uint64
+	cookie     uint
+	clients    []string
+	invalid    int
+}
+
+// verifyClient checks a client parameter as verify_rpc_payment_signature of
+// monerod does and returns its public key and timestamp.
+func verifyClient(client string, now time.Time) (string, uint64, bool) {
+	if len(client) != 208 {
+		return "", 0, false
+	}
+
+	pub, err := hex.DecodeString(client[:64])
+	if err != nil {
+		return "", 0, false
+	}
+	ts, err := strconv.ParseUint(client[64:80], 16, 64)
+	if err != nil || ts == 0 {
+		return "", 0, false
+	}
+	c, err1 := hex.DecodeString(client[80:144])
+	r, err2 := hex.DecodeString(client[144:])
+	if err1 != nil || err2 != nil {
+		return "", 0, false
+	}
+
+	// check_signature: c == H(hash || pub || c*P + r*G).
+	P, err := new(edwards25519.Point).SetBytes(pub)
+	if err != nil {
+		return "", 0, false
+	}
+	cs, err1 := edwards25519.NewScalar().SetCanonicalBytes(c)
+	rs, err2 := edwards25519.NewScalar().SetCanonicalBytes(r)
+	if err1 != nil || err2 != nil {
+		return "", 0, false
+	}
+
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(client[64:80]))
+	comm := new(edwards25519.Point).VarTimeDoubleScalarBaseMult(cs, P, rs).Bytes()
+	if scalarReduce(keccak(hash.Sum(nil), pub, comm)).Equal(cs) != 1 {
+		return "", 0, false
+	}
+
+	if leeway := uint64(60 * time.Second / time.Microsecond); ts+leeway < uint64(now.UnixMicro()) || ts > uint64(now.UnixMicro())+leeway {
+		return "", 0, false
+	}
+
+	return client[:64], ts, true
+}
+
+// verify checks client and rejects a timestamp not greater than the last one
+// of the same key.
+func (d *paidDaemon) verify(client string) (string, bool) {
+	key, ts, ok := verifyClient(client, time.Now())
+	if !ok || ts <= d.timestamps[key] {
+		d.invalid++
+		return "", false
+	}
+
+	d.timestamps[key] = ts
+	return key, true
+}
+
+func (d *paidDaemon) handler(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	body, _ := ioutil.ReadAll(r.Body)
+
+	if r.URL.Path == "/get_blocks.bin" {
+		var req map[string]interface{}
+		epee.Unmarshal(body, &req)
+
+		client, _ := req["client"].([]byte)
+		key, ok := d.verify(string(client))
+		ok = ok && d.charge(key)
+		res := BlocksBinResponse{Status: "OK", Credits: d.credits[key], TopHash: "top"}
+		if !ok {
+			res.Status = "PAYMENT REQUIRED"
+		}
+
+		data, _ := epee.Marshal(res)
+		w.Write(data)
+		return
+	}
+
+	var req struct {
+		ID     uint64 `json:"id"`
+		Method string `json:"method"`
+		Params struct {
+			Client string `json:"client"`
+			Nonce  uint   `json:"nonce"`
+			Cookie uint   `json:"cookie"`
+		} `json:"params"`
+	}
+	json.Unmarshal(body, &req)
+
+	reply := func(result interface{}, err *jsonRPCError) {
+		res := map[string]interface{}{"id": req.ID, "jsonrpc": "2.0", "result": result}
+		if err != nil {
+			res = map[string]interface{}{"id": req.ID, "jsonrpc": "2.0", "error": err}
+		}
+		json.NewEncoder(w).Encode(res)
+	}
+
+	key, valid := d.verify(req.Params.Client)
+	d.clients = append(d.clients, req.Params.Client)
+	if !valid {
+		reply(nil, &jsonRPCError{Code: ErrInvalidClient.Code, Message: ErrInvalidClient.Message})
+		return
+	}
+
+	switch req.Method {
+	case "rpc_access_info":
+		reply(RPCAccessInfoResponse{Cookie: d.cookie, Credits: d.credits[key], Diff: 100, CreditsPerHashFound: nonceCredits, Status: "OK", TopHash: "top"}, nil)
+	case "rpc_access_submit_nonce":
+		switch {
+		case req.Params.Cookie != d.cookie:
+			reply(nil, &jsonRPCError{Code: ErrStalePayment.Code, Message: ErrStalePayment.Message})
+		case req.Params.Nonce%2 != 0:
+			reply(nil, &jsonRPCError{Code: ErrPaymentTooLow.Code, Message: ErrPaymentTooLow.Message})
+		default:
+			d.credits[key] += nonceCredits
+			reply(RPCAccessResponse{Credits: d.credits[key], Status: "OK", TopHash: "top"}, nil)
+		}
+	default:
+		status := "OK"
+		if !d.charge(key) {
+			status = "PAYMENT REQUIRED"
+		}
+		reply(map[string]interface{}{"status": status, "credits": d.credits[key], "top_hash": "top"}, nil)
+	}
+}
+
+func (d *paidDaemon) charge(key string) bool {
+	if d.credits[key] < paidCallCost {
+		return false
+	}
+
+	d.credits[key] -= paidCallCost
+	return true
+}
+
+type paymentTestSuite struct {
+	suite.Suite
+	daemon *paidDaemon
+	ts     *httptest.Server
+}
+
+func (s *paymentTestSuite) SetupTest() {
+	s.daemon = &paidDaemon{credits: map[string]uint{}, timestamps: map[string]uint64{}, cookie: 7}
+	s.ts = httptest.NewServer(http.HandlerFunc(s.daemon.handler))
+}
+
+func (s *paymentTestSuite) TearDownTest() {
+	s.ts.Close()
+}
+
+func TestPaymentTestSuite(t *testing.T) {
+	suite.Run(t, new(paymentTestSuite))
+}
+
+func (s *paymentTestSuite) TestSignature() {
+	now := time.Unix(0x5f000000, 0)
+	p := newPayment(append([]byte{1}, make([]byte, 31)...))
+	p.now = func() time.Time { return now }
+
+	client := p.signature()
+	assert.Equal(s.T(), "5866666666666666666666666666666666666666666666666666666666666666", client[:64])
+	assert.Equal(s.T(), "0005a995c0000000", client[64:80])
+
+	key, ts, ok := verifyClient(client, now)
+	assert.True(s.T(), ok)
+	assert.Equal(s.T(), client[:64], key)
+	assert.Equal(s.T(), uint64(now.UnixMicro()), ts)
+
+	_, _, ok = verifyClient(client[:200]+"00000000", now)
+	assert.False(s.T(), ok)
+	_, _, ok = verifyClient(client, now.Add(2*time.Minute))
+	assert.False(s.T(), ok)
+
+	// Timestamps keep increasing within the same microsecond.
+	assert.Equal(s.T(), "0005a995c0000001", p.signature()[64:80])
+}
+
+func (s *paymentTestSuite) TestSign() {
+	p := newPayment(nil)
+
+	params, err := p.sign("/json_rpc", struct {
+		Height uint `json:"height"`
+	}{Height: 1 << 60})
+	assert.NoError(s.T(), err)
+
+	raw, _ := json.Marshal(params)
+	var values map[string]interface{}
+	json.Unmarshal(raw, &values)
+	_, _, ok := verifyClient(values["client"].(string), time.Now())
+	assert.True(s.T(), ok)
+	assert.Contains(s.T(), string(raw), `"height":1152921504606846976`)
+
+	params, err = p.sign("/json_rpc", nil)
+	assert.NoError(s.T(), err)
+	assert.Contains(s.T(), params, "client")
+
+	params, err = p.sign("/json_rpc", []int{1})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []int{1}, params)
+
+	params, err = p.sign("/json_rpc", map[string]string{"client": "mine"})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), map[string]string{"client": "mine"}, params)
+}
+
+func (s *paymentTestSuite) TestCredits() {
+	dc := NewDaemonClient(s.ts.URL, "", "", WithRPCPayment(nil))
+
+	_, err := dc.GetInfo()
+	assert.ErrorIs(s.T(), err, ErrStatusPayment)
+
+	credits, topHash := dc.Credits()
+	assert.Equal(s.T(), uint(0), credits)
+	assert.Equal(s.T(), "top", topHash)
+
+	s.daemon.mu.Lock()
+	key, _, _ := verifyClient(s.daemon.clients[0], time.Now())
+	s.daemon.credits[key] = 35
+	s.daemon.mu.Unlock()
+
+	_, err = dc.GetInfo()
+	assert.NoError(s.T(), err)
+	credits, _ = dc.Credits()
+	assert.Equal(s.T(), uint(25), credits)
+
+	_, err = dc.GetBlocksBin(1, nil, false, false, BlocksOnly, 0)
+	assert.NoError(s.T(), err)
+	credits, _ = dc.Credits()
+	assert.Equal(s.T(), uint(15), credits)
+	assert.Zero(s.T(), s.daemon.invalid)
+}
+
+func (s *paymentTestSuite) TestWithoutPayment() {
+	dc := NewDaemonClient(s.ts.URL, "", "")
+
+	_, err := dc.GetInfo()
+	assert.ErrorIs(s.T(), err, ErrInvalidClient)
+
+	credits, topHash := dc.Credits()
+	assert.Equal(s.T(), uint(0), credits)
+	assert.Equal(s.T(), "", topHash)
+
+	assert.ErrorIs(s.T(), dc.MineCredits(context.Background(), nil, 0), ErrPaymentDisabled)
+}
+
+func (s *paymentTestSuite) TestMineCredits() {
+	dc := NewDaemonClient(s.ts.URL, "", "", WithRPCPayment(nil))
+
+	var rounds int
+	miner := func(ctx context.Context, info RPCAccessInfoResponse) ([]uint, error) {
+		rounds++
+		assert.Equal(s.T(), uint(7), info.Cookie)
+		return []uint{uint(rounds), uint(rounds * 2)}, nil
+	}
+
+	err := dc.MineCredits(context.Background(), miner, 60)
+	assert.ErrorIs(s.T(), err, ErrPaymentTooLow)
+	assert.Equal(s.T(), 1, rounds)
+
+	err = dc.MineCredits(context.Background(), func(ctx context.Context, info RPCAccessInfoResponse) ([]uint, error) {
+		return []uint{2, 4}, nil
+	}, 60)
+	assert.NoError(s.T(), err)
+
+	credits, _ := dc.Credits()
+	assert.Equal(s.T(), uint(100), credits)
+	assert.Zero(s.T(), s.daemon.invalid)
+}
+
+func (s *paymentTestSuite) TestMineCreditsStale() {
+	dc := NewDaemonClient(s.ts.URL, "", "", WithRPCPayment(nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var rounds int
+	err := dc.MineCredits(ctx, func(ctx context.Context, info RPCAccessInfoResponse) ([]uint, error) {
+		rounds++
+		if rounds == 1 {
+			s.daemon.mu.Lock()
+			s.daemon.cookie++
+			s.daemon.mu.Unlock()
+			return []uint{2, 4}, nil
+		}
+		if rounds == 3 {
+			cancel()
+		}
+		return nil, nil
+	}, 0)
+	assert.ErrorIs(s.T(), err, context.Canceled)
+	assert.Equal(s.T(), 3, rounds)
+
+	credits, _ := dc.Credits()
+	assert.Equal(s.T(), uint(0), credits)
+}
@@ -0,0 +1,255 @@
+package xmrrpc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+const defaultMaxHeightLag = 2
+
+var ErrNoNodes = errors.New("No nodes in pool")
+
+type NodeStatus struct {
+	Endpoint  string
+	Healthy   bool
+	Height    uint
+	Latency   time.Duration
+	Err       error
+	CheckedAt time.Time
+}
+
+type poolNode struct {
+	client *DaemonClient
+	status NodeStatus
+}
+
+type NodePool struct {
+	*DaemonClient
+
+	mu           sync.RWMutex
+	nodes        []*poolNode
+	maxHeightLag uint
+	retryUnsafe  bool
+	interval     time.Duration
+	stop         chan struct{}
+	done         chan struct{}
+	closeOnce    sync.Once
+}
+
+type PoolOption func(p *NodePool)
+
+func WithHealthCheckInterval(interval time.Duration) PoolOption {
+	return func(p *NodePool) {
+		p.interval = interval
+	}
+}
+
+func WithMaxHeightLag(lag uint) PoolOption {
+	return func(p *NodePool) {
+		p.maxHeightLag = lag
+	}
+}
+
+func WithUnsafeRetries() PoolOption {
+	return func(p *NodePool) {
+		p.retryUnsafe = true
+	}
+}
+
+func NewNodePool(nodes []*DaemonClient, opts ...PoolOption) *NodePool {
+	p := &NodePool{
+		maxHeightLag: defaultMaxHeightLag,
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+
+	for _, client := range nodes {
+		p.nodes = append(p.nodes, &poolNode{client: client, status: NodeStatus{Endpoint: client.Endpoint(), Healthy: true}})
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	p.DaemonClient = NewDaemonClient("", "", "")
+	p.router = p.route
+
+	if p.interval > 0 {
+		go p.run()
+	} else {
+		close(p.done)
+	}
+
+	return p
+}
+
+func (p *NodePool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.stop)
+		<-p.done
+	})
+}
+
+func (p *NodePool) Nodes() []NodeStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	result := make([]NodeStatus, len(p.nodes))
+	for i, node := range p.nodes {
+		result[i] = node.status
+	}
+
+	return result
+}
+
+// NewBatch returns a batch for the best node, the batch of an empty pool fails
+// with ErrNoNodes.
+func (p *NodePool) NewBatch() *Batch {
+	nodes := p.ordered()
+	if len(nodes) == 0 {
+		return p.DaemonClient.NewBatch()
+	}
+
+	return nodes[0].client.NewBatch()
+}
+
+func (p *NodePool) CheckHealth(ctx context.Context) error {
+	statuses := make([]NodeStatus, len(p.nodes))
+	synced := make([]bool, len(p.nodes))
+
+	var wg sync.WaitGroup
+	for i, node := range p.nodes {
+		wg.Add(1)
+		go func(i int, node *poolNode) {
+			defer wg.Done()
+
+			start := time.Now()
+			info, err := node.client.GetInfoContext(ctx)
+			statuses[i] = NodeStatus{
+				Endpoint:  node.client.Endpoint(),
+				Height:    info.Height,
+				Latency:   time.Since(start),
+				Err:       err,
+				CheckedAt: time.Now(),
+			}
+			synced[i] = err == nil && !info.Offline && !info.BusySyncing && (info.Synchronized || info.TargetHeight <= info.Height)
+		}(i, node)
+	}
+	wg.Wait()
+
+	var maxHeight uint
+	for i, status := range statuses {
+		if synced[i] && status.Height > maxHeight {
+			maxHeight = status.Height
+		}
+	}
+
+	healthy := 0
+	p.mu.Lock()
+	for i, node := range p.nodes {
+		statuses[i].Healthy = synced[i] && statuses[i].Height+p.maxHeightLag >= maxHeight
+		node.status = statuses[i]
+		if node.status.Healthy {
+			healthy++
+		}
+	}
+	p.mu.Unlock()
+
+	if len(p.nodes) == 0 {
+		return ErrNoNodes
+	}
+
+	if healthy == 0 {
+		return errors.New("No healthy nodes in pool")
+	}
+
+	return nil
+}
+
+func (p *NodePool) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), p.interval)
+		p.CheckHealth(ctx)
+		cancel()
+
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *NodePool) ordered() []*poolNode {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	nodes := make([]*poolNode, len(p.nodes))
+	copy(nodes, p.nodes)
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		a, b := nodes[i].status, nodes[j].status
+		if a.Healthy != b.Healthy {
+			return a.Healthy
+		}
+		if a.Height != b.Height {
+			return a.Height > b.Height
+		}
+		return a.Latency < b.Latency
+	})
+
+	return nodes
+}
+
+func (p *NodePool) markFailed(node *poolNode, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	node.status.Healthy = false
+	node.status.Err = err
+}
+
+func (p *NodePool) route(ctx context.Context, inv *Invocation) error {
+	nodes := p.ordered()
+	if len(nodes) == 0 {
+		return ErrNoNodes
+	}
+
+	var err error
+	for i, node := range nodes {
+		if i > 0 {
+			resetResult(inv.Result)
+		}
+
+		// An open circuit is not worth retrying on the same node, but the
+		// request was not sent, so the next node may serve it.
+		err = node.client.invoke(ctx, inv)
+		if err == nil || ctx.Err() != nil || !(IsRetryable(err) || errors.Is(err, ErrCircuitOpen)) {
+			return err
+		}
+
+		p.markFailed(node, err)
+
+		if !p.retryUnsafe && !inv.retryAllowed(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func resetResult(result interface{}) {
+	v := reflect.ValueOf(result)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v.Elem().Set(reflect.Zero(v.Elem().Type()))
+	}
+}
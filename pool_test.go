@@ -0,0 +1,239 @@
+package xmrrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type fakeNode struct {
+	mu     sync.Mutex
+	ts     *httptest.Server
+	info   InfoResponse
+	status int
+	hits   map[string]int
+}
+
+func newFakeNode(height uint) *fakeNode {
+	n := &fakeNode{info: InfoResponse{Height: height, Synchronized: true, Status: "OK"}, status: http.StatusOK, hits: map[string]int{}}
+	n.ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+
+		method := r.URL.Path
+		req := &jsonRPCRequest{}
+		if method == "/json_rpc" {
+			json.NewDecoder(r.Body).Decode(req)
+			method = req.Method
+		}
+		n.hits[method]++
+
+		if n.status != http.StatusOK {
+			w.WriteHeader(n.status)
+			return
+		}
+
+		var result interface{} = statusOkResponse
+		switch method {
+		case "get_info":
+			result = n.info
+		case "submit_block":
+			json.NewEncoder(w).Encode(&jsonRPCResponse{ID: req.ID, Version: "2.0", Error: *statusErrorResponse})
+			return
+		}
+
+		if r.URL.Path == "/json_rpc" {
+			raw, _ := json.Marshal(result)
+			result = &jsonRPCResponse{ID: req.ID, Version: "2.0", Result: raw}
+		}
+		json.NewEncoder(w).Encode(result)
+	}))
+
+	return n
+}
+
+func (n *fakeNode) set(f func(n *fakeNode)) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f(n)
+}
+
+func (n *fakeNode) count(method string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.hits[method]
+}
+
+type poolTestSuite struct {
+	suite.Suite
+	nodes []*fakeNode
+	pool  *NodePool
+}
+
+func TestPoolTestSuite(t *testing.T) {
+	suite.Run(t, new(poolTestSuite))
+}
+
+func (s *poolTestSuite) SetupTest() {
+	s.nodes = []*fakeNode{newFakeNode(100), newFakeNode(102), newFakeNode(101)}
+}
+
+func (s *poolTestSuite) TearDownTest() {
+	if s.pool != nil {
+		s.pool.Close()
+	}
+
+	for _, n := range s.nodes {
+		n.ts.Close()
+	}
+}
+
+func (s *poolTestSuite) newPool(opts ...PoolOption) *NodePool {
+	var clients []*DaemonClient
+	for _, n := range s.nodes {
+		clients = append(clients, NewDaemonClient(n.ts.URL, "username", "password"))
+	}
+
+	s.pool = NewNodePool(clients, opts...)
+	return s.pool
+}
+
+func (s *poolTestSuite) TestCheckHealth() {
+	s.nodes[1].set(func(n *fakeNode) { n.info.Offline = true })
+
+	pool := s.newPool(WithMaxHeightLag(0))
+	if assert.NoError(s.T(), pool.CheckHealth(context.Background())) {
+		nodes := pool.Nodes()
+		assert.False(s.T(), nodes[0].Healthy, "Lagging node must be unhealthy.")
+		assert.False(s.T(), nodes[1].Healthy, "Offline node must be unhealthy.")
+		assert.True(s.T(), nodes[2].Healthy)
+		assert.Equal(s.T(), uint(101), nodes[2].Height)
+	}
+
+	_, err := pool.GetHeight()
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), 1, s.nodes[2].count("/get_height"))
+	}
+
+	s.nodes[0].set(func(n *fakeNode) { n.info.Offline = true })
+	s.nodes[2].set(func(n *fakeNode) { n.info.Synchronized, n.info.TargetHeight = false, 200 })
+	assert.Error(s.T(), pool.CheckHealth(context.Background()))
+}
+
+func (s *poolTestSuite) TestRouteHighest() {
+	pool := s.newPool()
+	if assert.NoError(s.T(), pool.CheckHealth(context.Background())) {
+		_, err := pool.GetBlockCount()
+		if assert.NoError(s.T(), err) {
+			assert.Equal(s.T(), 1, s.nodes[1].count("get_block_count"))
+		}
+
+		if assert.NoError(s.T(), pool.NewBatch().Send()) {
+			assert.Equal(s.T(), uint(102), pool.Nodes()[1].Height)
+		}
+	}
+}
+
+func (s *poolTestSuite) TestFailover() {
+	s.nodes[0].set(func(n *fakeNode) { n.status = http.StatusBadGateway })
+
+	pool := s.newPool()
+	res, err := pool.GetInfo()
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), uint(102), res.Height)
+		assert.Equal(s.T(), 1, s.nodes[0].count("get_info"))
+		assert.False(s.T(), pool.Nodes()[0].Healthy)
+	}
+
+	_, err = pool.SubmitBlock("0707e6bdfedc0...")
+	if assert.True(s.T(), errors.Is(err, ErrBlockNotAccepted)) {
+		assert.Equal(s.T(), 1, s.nodes[1].count("submit_block")+s.nodes[2].count("submit_block"), "RPC errors must not fail over.")
+	}
+}
+
+func (s *poolTestSuite) TestFailoverCircuitOpen() {
+	breaker := NewDaemonClient(s.nodes[0].ts.URL, "username", "password", WithCircuitBreaker(CircuitBreakerConfig{Window: 2, FailureRate: 0.5, CoolDown: time.Minute}))
+
+	s.nodes[0].set(func(n *fakeNode) { n.status = http.StatusBadGateway })
+	for i := 0; i < 2; i++ {
+		breaker.GetBlockCount()
+	}
+	assert.Equal(s.T(), CircuitOpen, breaker.CircuitState())
+	s.nodes[0].set(func(n *fakeNode) { n.status = http.StatusOK })
+
+	s.pool = NewNodePool([]*DaemonClient{breaker, NewDaemonClient(s.nodes[1].ts.URL, "username", "password")})
+	_, err := s.pool.GetBlockCount()
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), 2, s.nodes[0].count("get_block_count"), "Open circuit must not reach the node.")
+		assert.Equal(s.T(), 1, s.nodes[1].count("get_block_count"))
+
+		nodes := s.pool.Nodes()
+		assert.False(s.T(), nodes[0].Healthy)
+		assert.ErrorIs(s.T(), nodes[0].Err, ErrCircuitOpen)
+	}
+}
+
+func (s *poolTestSuite) TestFailoverUnsafe() {
+	for _, n := range s.nodes {
+		n.set(func(n *fakeNode) { n.status = http.StatusServiceUnavailable })
+	}
+
+	pool := s.newPool()
+	_, err := pool.SendRawTransaction("", false)
+	if assert.Error(s.T(), err) {
+		assert.Equal(s.T(), 1, s.nodes[0].count("/send_raw_transaction")+s.nodes[1].count("/send_raw_transaction")+s.nodes[2].count("/send_raw_transaction"))
+	}
+
+	_, err = pool.GetHeight()
+	if assert.Error(s.T(), err) {
+		for _, n := range s.nodes {
+			assert.Equal(s.T(), 1, n.count("/get_height"))
+		}
+	}
+
+	pool.Close()
+	pool = s.newPool(WithUnsafeRetries())
+	_, err = pool.SendRawTransaction("", false)
+	if assert.Error(s.T(), err) {
+		assert.Equal(s.T(), 4, s.nodes[0].count("/send_raw_transaction")+s.nodes[1].count("/send_raw_transaction")+s.nodes[2].count("/send_raw_transaction"))
+	}
+}
+
+func (s *poolTestSuite) TestHealthCheckInterval() {
+	pool := s.newPool(WithHealthCheckInterval(10 * time.Millisecond))
+
+	assert.Eventually(s.T(), func() bool {
+		return s.nodes[0].count("get_info") >= 2
+	}, time.Second, 5*time.Millisecond)
+
+	pool.Close()
+	pool.Close()
+
+	n := s.nodes[0].count("get_info")
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(s.T(), n, s.nodes[0].count("get_info"))
+}
+
+func (s *poolTestSuite) TestEmpty() {
+	s.pool = NewNodePool(nil)
+	_, err := s.pool.GetInfo()
+	assert.Equal(s.T(), ErrNoNodes, err)
+	assert.Equal(s.T(), ErrNoNodes, s.pool.CheckHealth(context.Background()))
+
+	b := s.pool.NewBatch()
+	b.Add("get_info", nil, new(InfoResponse))
+	assert.Equal(s.T(), ErrNoNodes, b.Send())
+
+	_, err = s.pool.GetBlocksBin(0, nil, false, false, BlocksOnly, 0)
+	assert.Equal(s.T(), ErrNoNodes, err)
+}
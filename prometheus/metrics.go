@@ -0,0 +1,95 @@
+package prometheus
+
+import (
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/stdfox/xmrrpc"
+)
+
+// Metrics implements xmrrpc.Metrics on top of the Prometheus client library,
+// it is a prom.Collector and has to be registered.
+type Metrics struct {
+	requests *prom.CounterVec
+	errors   *prom.CounterVec
+	latency  *prom.HistogramVec
+	inFlight *prom.GaugeVec
+	retries  *prom.CounterVec
+	reauths  prom.Counter
+}
+
+var _ xmrrpc.Metrics = (*Metrics)(nil)
+
+func NewMetrics(namespace string) *Metrics {
+	if namespace == "" {
+		namespace = "xmrrpc"
+	}
+
+	return &Metrics{
+		requests: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Number of daemon RPC calls.",
+		}, []string{"method"}),
+		errors: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "errors_total",
+			Help:      "Number of failed daemon RPC calls by error class.",
+		}, []string{"method", "class"}),
+		latency: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Duration of daemon RPC calls, including retries.",
+			Buckets:   prom.ExponentialBuckets(0.005, 2, 12),
+		}, []string{"method"}),
+		inFlight: prom.NewGaugeVec(prom.GaugeOpts{
+			Namespace: namespace,
+			Name:      "in_flight_requests",
+			Help:      "Number of daemon RPC calls in progress.",
+		}, []string{"method"}),
+		retries: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "retries_total",
+			Help:      "Number of retried daemon RPC attempts.",
+		}, []string{"method"}),
+		reauths: prom.NewCounter(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "digest_reauths_total",
+			Help:      "Number of digest authentication handshakes.",
+		}),
+	}
+}
+
+func (m *Metrics) InFlight(method string, delta int) {
+	m.inFlight.WithLabelValues(method).Add(float64(delta))
+}
+
+func (m *Metrics) Observe(method string, duration time.Duration, err error) {
+	m.requests.WithLabelValues(method).Inc()
+	m.latency.WithLabelValues(method).Observe(duration.Seconds())
+	if err != nil {
+		m.errors.WithLabelValues(method, xmrrpc.ErrorClass(err)).Inc()
+	}
+}
+
+func (m *Metrics) Retry(method string) {
+	m.retries.WithLabelValues(method).Inc()
+}
+
+func (m *Metrics) Reauth() {
+	m.reauths.Inc()
+}
+
+func (m *Metrics) Describe(ch chan<- *prom.Desc) {
+	m.collectors(func(c prom.Collector) { c.Describe(ch) })
+}
+
+func (m *Metrics) Collect(ch chan<- prom.Metric) {
+	m.collectors(func(c prom.Collector) { c.Collect(ch) })
+}
+
+func (m *Metrics) collectors(f func(c prom.Collector)) {
+	for _, c := range []prom.Collector{m.requests, m.errors, m.latency, m.inFlight, m.retries, m.reauths} {
+		f(c)
+	}
+}
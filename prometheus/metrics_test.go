@@ -0,0 +1,67 @@
+package prometheus
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stdfox/xmrrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type metricsTestSuite struct {
+	suite.Suite
+}
+
+func TestMetricsTestSuite(t *testing.T) {
+	suite.Run(t, new(metricsTestSuite))
+}
+
+func (s *metricsTestSuite) TestMetrics() {
+	m := NewMetrics("")
+	reg := prom.NewPedanticRegistry()
+	assert.NoError(s.T(), reg.Register(m))
+
+	m.InFlight("get_info", 1)
+	m.InFlight("get_info", 1)
+	m.InFlight("get_info", -1)
+	m.Observe("get_info", 10*time.Millisecond, nil)
+	m.Observe("get_info", 20*time.Millisecond, xmrrpc.ErrCoreBusy)
+	m.Observe("get_height", time.Millisecond, errors.New("Unexpected null result"))
+	m.Retry("get_info")
+	m.Reauth()
+
+	expected := `
+# HELP xmrrpc_errors_total Number of failed daemon RPC calls by error class.
+# TYPE xmrrpc_errors_total counter
+xmrrpc_errors_total{class="other",method="get_height"} 1
+xmrrpc_errors_total{class="rpc",method="get_info"} 1
+# HELP xmrrpc_in_flight_requests Number of daemon RPC calls in progress.
+# TYPE xmrrpc_in_flight_requests gauge
+xmrrpc_in_flight_requests{method="get_info"} 1
+# HELP xmrrpc_requests_total Number of daemon RPC calls.
+# TYPE xmrrpc_requests_total counter
+xmrrpc_requests_total{method="get_height"} 1
+xmrrpc_requests_total{method="get_info"} 2
+# HELP xmrrpc_retries_total Number of retried daemon RPC attempts.
+# TYPE xmrrpc_retries_total counter
+xmrrpc_retries_total{method="get_info"} 1
+# HELP xmrrpc_digest_reauths_total Number of digest authentication handshakes.
+# TYPE xmrrpc_digest_reauths_total counter
+xmrrpc_digest_reauths_total 1
+`
+	assert.NoError(s.T(), testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"xmrrpc_errors_total", "xmrrpc_in_flight_requests", "xmrrpc_requests_total", "xmrrpc_retries_total", "xmrrpc_digest_reauths_total"))
+	assert.Equal(s.T(), 2, testutil.CollectAndCount(m, "xmrrpc_request_duration_seconds"))
+}
+
+func (s *metricsTestSuite) TestNamespace() {
+	m := NewMetrics("monerod")
+	m.Reauth()
+	assert.Equal(s.T(), float64(1), testutil.ToFloat64(m.reauths))
+	assert.Equal(s.T(), 1, testutil.CollectAndCount(m, "monerod_digest_reauths_total"))
+}
@@ -0,0 +1,28 @@
+package xmrrpc
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+var ErrProxyRequired = errors.New("Proxy is required for .onion and .i2p hosts")
+
+// WithSOCKS5Proxy routes requests through a SOCKS5 proxy such as Tor. Every client
+// authenticates with its own random credentials, which Tor uses to isolate streams.
+// Hostnames are resolved by the proxy.
+func WithSOCKS5Proxy(address string) Option {
+	return func(dc *DaemonClient) {
+		dc.proxy = &url.URL{Scheme: "socks5", Host: address, User: url.UserPassword(randomKey(), randomKey())}
+	}
+}
+
+func isAnonymousHost(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return false
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	return strings.HasSuffix(host, ".onion") || strings.HasSuffix(host, ".i2p")
+}
@@ -0,0 +1,180 @@
+package xmrrpc
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// socks5Server is a minimal SOCKS5 stand-in with username/password authentication
+// that connects every CONNECT request to target.
+type socks5Server struct {
+	listener net.Listener
+	target   string
+	mu       sync.Mutex
+	users    []string
+	hosts    []string
+}
+
+func newSOCKS5Server(target string) *socks5Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+
+	s := &socks5Server{listener: listener, target: target}
+	go s.serve()
+
+	return s
+}
+
+func (s *socks5Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *socks5Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	buf := make([]byte, 262)
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil || buf[0] != 5 {
+		return
+	}
+	if _, err := io.ReadFull(conn, buf[:buf[1]]); err != nil {
+		return
+	}
+	conn.Write([]byte{5, 2})
+
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		return
+	}
+	user := make([]byte, buf[1])
+	io.ReadFull(conn, user)
+	io.ReadFull(conn, buf[:1])
+	io.ReadFull(conn, make([]byte, buf[0]))
+	conn.Write([]byte{1, 0})
+
+	if _, err := io.ReadFull(conn, buf[:4]); err != nil || buf[1] != 1 || buf[3] != 3 {
+		conn.Write([]byte{5, 8, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	io.ReadFull(conn, buf[:1])
+	host := make([]byte, buf[0])
+	io.ReadFull(conn, host)
+	io.ReadFull(conn, buf[:2])
+	port := binary.BigEndian.Uint16(buf[:2])
+
+	s.mu.Lock()
+	s.users = append(s.users, string(user))
+	s.hosts = append(s.hosts, net.JoinHostPort(string(host), strconv.Itoa(int(port))))
+	s.mu.Unlock()
+
+	target, err := net.Dial("tcp", s.target)
+	if err != nil {
+		conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer target.Close()
+	conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+
+	go io.Copy(target, conn)
+	io.Copy(conn, target)
+}
+
+type proxyTestSuite struct {
+	suite.Suite
+	ts    *httptest.Server
+	proxy *socks5Server
+}
+
+func TestProxyTestSuite(t *testing.T) {
+	suite.Run(t, new(proxyTestSuite))
+}
+
+func (s *proxyTestSuite) SetupTest() {
+	s.ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"OK","height":100}`))
+	}))
+	s.proxy = newSOCKS5Server(s.ts.Listener.Addr().String())
+}
+
+func (s *proxyTestSuite) TearDownTest() {
+	s.proxy.listener.Close()
+	s.ts.Close()
+}
+
+func (s *proxyTestSuite) TestOnion() {
+	const onion = "xmrrpcxmrrpcxmrrpcxmrrpcxmrrpcxmrrpcxmrrpcxmrrpcxmrrpcid.onion"
+
+	dc1 := NewDaemonClient("http://"+onion+":18089", "username", "password", WithSOCKS5Proxy(s.proxy.listener.Addr().String()))
+	dc2 := NewDaemonClient("http://"+onion+":18089", "username", "password", WithSOCKS5Proxy(s.proxy.listener.Addr().String()), WithTimeout(time.Second))
+
+	for _, dc := range []*DaemonClient{dc1, dc2} {
+		res, err := dc.GetHeight()
+		if assert.NoError(s.T(), err) {
+			assert.Equal(s.T(), uint(100), res.Height)
+		}
+	}
+
+	assert.Equal(s.T(), []string{onion + ":18089", onion + ":18089"}, s.proxy.hosts, "Hostname must be resolved by the proxy.")
+	if assert.Len(s.T(), s.proxy.users, 2) {
+		assert.NotEmpty(s.T(), s.proxy.users[0])
+		assert.NotEqual(s.T(), s.proxy.users[0], s.proxy.users[1], "Clients must use isolated streams.")
+	}
+
+	dc1.GetHeight()
+	assert.Len(s.T(), s.proxy.users, 2, "Connections must be reused.")
+}
+
+func (s *proxyTestSuite) TestProxyRequired() {
+	for _, endpoint := range []string{"http://example.onion", "http://EXAMPLE.ONION.:18081", "http://example.b32.i2p"} {
+		_, err := NewDaemonClient(endpoint, "username", "password").GetHeight()
+		assert.True(s.T(), errors.Is(err, ErrProxyRequired), endpoint)
+
+		_, err = NewDaemonClient(endpoint, "username", "password", WithTimeout(time.Second)).GetHeight()
+		assert.True(s.T(), errors.Is(err, ErrProxyRequired), endpoint)
+	}
+
+	dc := NewDaemonClient("http://example.onion", "username", "password", WithHTTPClient(&http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, errors.New("custom transport")
+	})}))
+	_, err := dc.GetHeight()
+	assert.EqualError(s.T(), errors.Unwrap(err), "custom transport")
+
+	var dialed bool
+	dc = NewDaemonClient("http://example.onion", "username", "password",
+		WithHTTPClient(&http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			dialed = true
+			return nil, errors.New("custom transport")
+		})}),
+		WithSOCKS5Proxy(s.proxy.listener.Addr().String()),
+	)
+	_, err = dc.GetHeight()
+	assert.ErrorIs(s.T(), err, ErrProxyRequired)
+	assert.False(s.T(), dialed, "The proxy of a custom transport cannot be set.")
+
+	_, err = NewDaemonClient(s.ts.URL, "username", "password").GetHeight()
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), s.proxy.hosts)
+}
+
+type roundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
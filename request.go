@@ -2,109 +2,72 @@ package xmrrpc
 
 import (
 	"bytes"
-	"crypto/md5"
-	"encoding/base64"
-	"encoding/hex"
-	"fmt"
+	"context"
 	"io"
 	"io/ioutil"
-	"math/rand"
 	"net/http"
 	"strings"
-	"time"
 )
 
-func digestAuthParams(response *http.Response) map[string]string {
-	s := strings.SplitN(response.Header.Get("WWW-Authenticate"), " ", 2)
-	if len(s) != 2 || s[0] != "Digest" {
-		return nil
+func newRequest(ctx context.Context, method string, url string, header http.Header, body []byte) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
 	}
+	req = req.WithContext(ctx)
 
-	result := map[string]string{}
-	for _, kv := range strings.Split(s[1], ",") {
-		parts := strings.SplitN(kv, "=", 2)
-		if len(parts) != 2 {
-			continue
-		}
-
-		result[strings.Trim(parts[0], "\" ")] = strings.Trim(parts[1], "\" ")
+	for k, v := range header {
+		req.Header[k] = v
 	}
-
-	return result
-}
-
-func randomKey() string {
-	k := make([]byte, 8)
-	if _, err := rand.Read(k); err != nil {
-		panic(err)
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
 	}
 
-	return base64.StdEncoding.EncodeToString(k)
+	return req, nil
 }
 
-func h(s string) string {
-	digest := md5.New()
-	if _, err := digest.Write([]byte(s)); err != nil {
-		panic(err)
-	}
-
-	return hex.EncodeToString(digest.Sum(nil))
-}
-
-func request(method string, url string, body []byte, username string, password string) (*http.Response, error) {
-	rand.Seed(time.Now().UnixNano())
-
-	req1, err := http.NewRequest(method, url, bytes.NewReader(body))
+func request(ctx context.Context, client *http.Client, method string, url string, header http.Header, body []byte, auth *digestAuth) (res *http.Response, err error) {
+	req1, err := newRequest(ctx, method, url, header, body)
 	if err != nil {
 		return nil, err
 	}
-	req1.Header.Set("Content-Type", "application/json")
 
-	res1, err := http.DefaultClient.Do(req1)
+	authHeader := auth.authorization(method, req1.URL.RequestURI(), body)
+	if authHeader != "" {
+		req1.Header.Set("Authorization", authHeader)
+	}
+
+	res1, err := client.Do(req1)
 	if err != nil {
 		return nil, err
 	}
 
-	if res1.StatusCode == http.StatusUnauthorized {
-		io.Copy(ioutil.Discard, res1.Body)
-		res1.Body.Close()
-
-		var authorization = digestAuthParams(res1)
-		var realmHeader = authorization["realm"]
-		var qopHeader = authorization["qop"]
-		var nonceHeader = authorization["nonce"]
-		var algorithm = authorization["algorithm"]
-		var realm = realmHeader
-		var nc = "00000001"
-
-		hash := md5.New()
-		a1 := fmt.Sprintf("%s:%s:%s", username, realm, password)
-		io.WriteString(hash, a1)
-		ha1 := hex.EncodeToString(hash.Sum(nil))
+	if res1.StatusCode != http.StatusUnauthorized {
+		return res1, nil
+	}
 
-		hash = md5.New()
-		a2 := fmt.Sprintf("%s:%s", method, "/json_rpc")
-		io.WriteString(hash, a2)
-		ha2 := hex.EncodeToString(hash.Sum(nil))
+	if authHeader != "" && !strings.EqualFold(digestAuthParams(res1)["stale"], "true") {
+		return res1, nil
+	}
 
-		cnonce := randomKey()
-		response := h(strings.Join([]string{ha1, nonceHeader, nc, cnonce, qopHeader, ha2}, ":"))
-		authHeader := fmt.Sprintf(`Digest username="%s", realm="%s", nonce="%s", uri="%s", algorithm="%s", response="%s", qop=%s, nc=%s, cnonce="%s"`, username, realmHeader, nonceHeader, "/json_rpc", algorithm, response, qopHeader, nc, cnonce)
+	if !auth.challenge(res1) {
+		return res1, nil
+	}
 
-		req2, err := http.NewRequest(method, url, bytes.NewReader(body))
-		if err != nil {
-			return nil, err
-		}
-		req2.Header.Set("Content-Type", "application/json")
-		req2.Header.Set("Authorization", authHeader)
+	io.Copy(ioutil.Discard, res1.Body)
+	res1.Body.Close()
 
-		res2, err := http.DefaultClient.Do(req2)
-		if err != nil {
-			return nil, err
-		}
+	if auth.onChallenge != nil {
+		var done func(err error)
+		ctx, done = auth.onChallenge(ctx)
+		defer func() { done(err) }()
+	}
 
-		return res2, nil
+	req2, err := newRequest(ctx, method, url, header, body)
+	if err != nil {
+		return nil, err
 	}
+	req2.Header.Set("Authorization", auth.authorization(method, req2.URL.RequestURI(), body))
 
-	return res1, nil
+	return client.Do(req2)
 }
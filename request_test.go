@@ -1,10 +1,13 @@
 package xmrrpc
 
 import (
-	"encoding/base64"
+	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -18,60 +21,131 @@ func TestRequestTestSuite(t *testing.T) {
 	suite.Run(t, new(requestTestSuite))
 }
 
-func (s *requestTestSuite) TestDigestAuthParams() {
-	res1 := &httptest.ResponseRecorder{}
-	res1.Header().Add("WWW-authenticate", `NotDigest qop="auth",algorithm=MD5`)
-	if assert.Empty(s.T(), digestAuthParams(res1.Result())) {
-		res2 := &httptest.ResponseRecorder{}
-		res2.Header().Add("WWW-authenticate", `Digest qop="auth",algorithm=MD5,realm="monero-rpc",nonce,stale=false`)
-		d := digestAuthParams(res2.Result())
-		if assert.Empty(s.T(), d["nonce"]) {
-			res3 := &httptest.ResponseRecorder{}
-			res3.Header().Add("WWW-authenticate", `Digest qop="auth",algorithm=MD5,realm="monero-rpc",nonce="jmk3hzH2xpPmUBSD0uy+uQ==",stale=false`)
-			d = digestAuthParams(res3.Result())
-			if assert.NotEmpty(s.T(), d) {
-				assert.Equal(s.T(), "jmk3hzH2xpPmUBSD0uy+uQ==", d["nonce"])
-			}
+func (s *requestTestSuite) TestRequest() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("WWW-authenticate", `Digest qop="auth",algorithm=MD5,realm="monero-rpc",nonce="jmk3hzH2xpPmUBSD0uy+uQ==",stale=false`)
+		w.Header().Add("WWW-authenticate", `Digest qop="auth",algorithm=MD5-sess,realm="monero-rpc",nonce="jmk3hzH2xpPmUBSD0uy+uQ==",stale=false`)
+
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+		} else {
+			w.WriteHeader(http.StatusOK)
 		}
+	}))
+	defer ts.Close()
+
+	_, err := request(context.Background(), http.DefaultClient, http.MethodPost, "", nil, nil, newDigestAuth("username", "password"))
+	assert.Error(s.T(), err)
+
+	res, err := request(context.Background(), http.DefaultClient, http.MethodPost, ts.URL, nil, nil, newDigestAuth("username", "password"))
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), http.StatusOK, res.StatusCode)
 	}
 }
 
-func (s *requestTestSuite) TestRandomKey() {
-	k := randomKey()
-	if assert.NotPanics(s.T(), func() { randomKey() }) {
-		if assert.Len(s.T(), k, 12, "Key length is incorrect.") {
-			d, err := base64.StdEncoding.DecodeString(k)
-			if assert.NoError(s.T(), err, "Key encode is incorrect.") {
-				assert.Len(s.T(), d, 8, "Key bytes length is incorrect.")
-			}
+func (s *requestTestSuite) TestRequestContext() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.Header().Add("WWW-authenticate", `Digest qop="auth",algorithm=MD5,realm="monero-rpc",nonce="jmk3hzH2xpPmUBSD0uy+uQ==",stale=false`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
 		}
+
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := request(ctx, http.DefaultClient, http.MethodPost, ts.URL, nil, nil, newDigestAuth("username", "password"))
+	assert.Error(s.T(), err)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = request(ctx, http.DefaultClient, http.MethodPost, ts.URL, nil, nil, newDigestAuth("username", "password"))
+	if assert.Error(s.T(), err) {
+		assert.Equal(s.T(), context.DeadlineExceeded, ctx.Err())
 	}
 }
 
-func (s *requestTestSuite) TestH() {
-	if assert.NotPanics(s.T(), func() { h("1234567890") }) {
-		assert.Equal(s.T(), "e807f1fcf82d132f9bb018ca6738a19f", h("1234567890"), "MD5 hash is incorrect.")
-	}
+func authorizationParams(r *http.Request) map[string]string {
+	return digestAuthParams(&http.Response{Header: http.Header{"Www-Authenticate": {r.Header.Get("Authorization")}}})
 }
 
-func (s *requestTestSuite) TestRequest() {
+func (s *requestTestSuite) TestRequestSession() {
+	var mu sync.Mutex
+	var nonce = 1
+	var stale = false
+	var hits []map[string]string
+
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Add("WWW-authenticate", `Digest qop="auth",algorithm=MD5,realm="monero-rpc",nonce="jmk3hzH2xpPmUBSD0uy+uQ==",stale=false`)
-		w.Header().Add("WWW-authenticate", `Digest qop="auth",algorithm=MD5-sess,realm="monero-rpc",nonce="jmk3hzH2xpPmUBSD0uy+uQ==",stale=false`)
+		mu.Lock()
+		defer mu.Unlock()
 
-		if r.Header.Get("Authorization") == "" {
+		params := authorizationParams(r)
+		hits = append(hits, params)
+
+		switch {
+		case params == nil:
+			w.Header().Add("WWW-authenticate", fmt.Sprintf(`Digest qop="auth",algorithm=MD5,realm="monero-rpc",nonce="nonce-%d",stale=false`, nonce))
 			w.WriteHeader(http.StatusUnauthorized)
-		} else {
+		case params["username"] != "username":
+			w.Header().Add("WWW-authenticate", fmt.Sprintf(`Digest qop="auth",algorithm=MD5,realm="monero-rpc",nonce="nonce-%d",stale=false`, nonce))
+			w.WriteHeader(http.StatusUnauthorized)
+		case params["nonce"] != fmt.Sprintf("nonce-%d", nonce):
+			w.Header().Add("WWW-authenticate", fmt.Sprintf(`Digest qop="auth",algorithm=MD5,realm="monero-rpc",nonce="nonce-%d",stale=%t`, nonce, stale))
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
 			w.WriteHeader(http.StatusOK)
 		}
 	}))
 	defer ts.Close()
 
-	_, err := request(http.MethodPost, "", nil, "username", "password")
-	assert.Error(s.T(), err)
+	auth := newDigestAuth("username", "password")
+	do := func() int {
+		res, err := request(context.Background(), http.DefaultClient, http.MethodPost, ts.URL, nil, nil, auth)
+		if assert.NoError(s.T(), err) {
+			res.Body.Close()
+			return res.StatusCode
+		}
+		return 0
+	}
 
-	res, err := request(http.MethodPost, ts.URL, nil, "username", "password")
-	if assert.NoError(s.T(), err) {
-		assert.Equal(s.T(), http.StatusOK, res.StatusCode)
+	assert.Equal(s.T(), http.StatusOK, do())
+	if assert.Len(s.T(), hits, 2) {
+		assert.Nil(s.T(), hits[0])
+		assert.Equal(s.T(), "00000001", hits[1]["nc"])
+	}
+
+	assert.Equal(s.T(), http.StatusOK, do())
+	if assert.Len(s.T(), hits, 3, "Authorization must be sent preemptively.") {
+		assert.Equal(s.T(), "nonce-1", hits[2]["nonce"])
+		assert.Equal(s.T(), "00000002", hits[2]["nc"])
+		assert.Equal(s.T(), hits[1]["cnonce"], hits[2]["cnonce"])
 	}
+
+	mu.Lock()
+	nonce, stale = 2, true
+	mu.Unlock()
+
+	assert.Equal(s.T(), http.StatusOK, do())
+	if assert.Len(s.T(), hits, 5) {
+		assert.Equal(s.T(), "nonce-1", hits[3]["nonce"])
+		assert.Equal(s.T(), "00000003", hits[3]["nc"])
+		assert.Equal(s.T(), "nonce-2", hits[4]["nonce"])
+		assert.Equal(s.T(), "00000001", hits[4]["nc"])
+	}
+
+	mu.Lock()
+	nonce, stale = 3, false
+	mu.Unlock()
+
+	assert.Equal(s.T(), http.StatusUnauthorized, do())
+	assert.Len(s.T(), hits, 6, "Handshake must be redone only on stale nonce.")
+
+	auth = newDigestAuth("wrong", "password")
+	assert.Equal(s.T(), http.StatusUnauthorized, do())
+	assert.Len(s.T(), hits, 8)
 }
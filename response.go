@@ -0,0 +1,113 @@
+package xmrrpc
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var ErrResponseTooLarge = errors.New("Response is too large")
+
+// WithMaxResponseSize limits the size of decoded response bodies, 0 means no limit.
+func WithMaxResponseSize(size int64) Option {
+	return func(dc *DaemonClient) {
+		dc.maxResponseSize = size
+	}
+}
+
+// WithMethodMaxResponseSize overrides the limit of WithMaxResponseSize for a method.
+func WithMethodMaxResponseSize(method string, size int64) Option {
+	return func(dc *DaemonClient) {
+		if dc.methodMaxResponseSize == nil {
+			dc.methodMaxResponseSize = map[string]int64{}
+		}
+		dc.methodMaxResponseSize[method] = size
+	}
+}
+
+// WithCompression asks the daemon for gzip or deflate compressed responses.
+func WithCompression() Option {
+	return func(dc *DaemonClient) {
+		dc.header.Set("Accept-Encoding", "gzip, deflate")
+	}
+}
+
+func (dc *DaemonClient) responseLimit(method string) int64 {
+	if size, ok := dc.methodMaxResponseSize[method]; ok {
+		return size
+	}
+
+	return dc.maxResponseSize
+}
+
+// sizeReader counts the bytes read and fails once more than limit bytes are read.
+type sizeReader struct {
+	r      io.Reader
+	method string
+	limit  int64
+	n      int64
+}
+
+func (r *sizeReader) Read(p []byte) (int, error) {
+	if r.limit > 0 && int64(len(p)) > r.limit-r.n+1 {
+		p = p[:r.limit-r.n+1]
+	}
+
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	if r.limit > 0 && r.n > r.limit {
+		return n, fmt.Errorf("%w: %s exceeds %d bytes", ErrResponseTooLarge, r.method, r.limit)
+	}
+
+	return n, err
+}
+
+func responseBody(res *http.Response) (io.ReadCloser, error) {
+	switch strings.ToLower(strings.TrimSpace(res.Header.Get("Content-Encoding"))) {
+	case "gzip":
+		return gzip.NewReader(res.Body)
+	case "deflate":
+		return zlib.NewReader(res.Body)
+	}
+
+	return res.Body, nil
+}
+
+func decodeResponse(r io.Reader, reply interface{}) error {
+	err := json.NewDecoder(r).Decode(reply)
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
+
+// replyDecoder decodes a JSON value straight into reply, without holding it as
+// raw JSON first, and records the credits and top hash found in it.
+type replyDecoder struct {
+	reply   interface{}
+	payment *payment
+	decoded bool
+}
+
+func (d *replyDecoder) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, d.reply); err != nil {
+		return err
+	}
+	d.decoded = true
+
+	if d.payment != nil {
+		d.payment.trackJSON(data)
+	}
+
+	return nil
+}
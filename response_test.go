@@ -0,0 +1,159 @@
+package xmrrpc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type responseTestSuite struct {
+	suite.Suite
+	ts     *httptest.Server
+	header http.Header
+}
+
+func TestResponseTestSuite(t *testing.T) {
+	suite.Run(t, new(responseTestSuite))
+}
+
+func (s *responseTestSuite) SetupTest() {
+	s.ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.header = r.Header
+
+		var body []byte
+		switch r.URL.Path {
+		case "/json_rpc":
+			var req jsonRPCRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			body = []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"status":"OK","blob":"%s"}}`, req.ID, strings.Repeat("00", 1024)))
+		case "/get_transaction_pool":
+			body = []byte(`{"status":"OK","transactions":[` + strings.Repeat(`{"id_hash":"00"},`, 1000) + `{"id_hash":"00"}]}`)
+		default:
+			body = []byte(`{"status":"OK","height":100}`)
+		}
+
+		var buf bytes.Buffer
+		switch r.Header.Get("Accept-Encoding") {
+		case "gzip, deflate":
+			w.Header().Set("Content-Encoding", "gzip")
+			zw := gzip.NewWriter(&buf)
+			zw.Write(body)
+			zw.Close()
+		case "deflate":
+			w.Header().Set("Content-Encoding", "deflate")
+			zw := zlib.NewWriter(&buf)
+			zw.Write(body)
+			zw.Close()
+		default:
+			buf.Write(body)
+		}
+		w.Write(buf.Bytes())
+	}))
+}
+
+func (s *responseTestSuite) TearDownTest() {
+	s.ts.Close()
+}
+
+func (s *responseTestSuite) TestMaxResponseSize() {
+	dc := NewDaemonClient(s.ts.URL, "username", "password", WithMaxResponseSize(1024))
+
+	res, err := dc.GetHeight()
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), uint(100), res.Height)
+	}
+
+	_, err = dc.GetTransactionPool()
+	assert.True(s.T(), errors.Is(err, ErrResponseTooLarge))
+	assert.Contains(s.T(), err.Error(), "get_transaction_pool")
+
+	_, err = dc.GetBlock(1, "")
+	assert.True(s.T(), errors.Is(err, ErrResponseTooLarge))
+	assert.Contains(s.T(), err.Error(), "get_block")
+}
+
+func (s *responseTestSuite) TestMethodMaxResponseSize() {
+	dc := NewDaemonClient(s.ts.URL, "username", "password",
+		WithMaxResponseSize(1024),
+		WithMethodMaxResponseSize("get_block", 4096),
+		WithMethodMaxResponseSize("get_height", 16),
+	)
+
+	_, err := dc.GetBlock(1, "")
+	assert.NoError(s.T(), err)
+
+	_, err = dc.GetHeight()
+	assert.True(s.T(), errors.Is(err, ErrResponseTooLarge))
+
+	_, err = NewDaemonClient(s.ts.URL, "username", "password").GetTransactionPool()
+	assert.NoError(s.T(), err)
+}
+
+func (s *responseTestSuite) TestCompression() {
+	dc := NewDaemonClient(s.ts.URL, "username", "password", WithCompression(), WithMaxResponseSize(1024))
+
+	res, err := dc.GetHeight()
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), uint(100), res.Height)
+	}
+	assert.Equal(s.T(), "gzip, deflate", s.header.Get("Accept-Encoding"))
+
+	_, err = dc.GetTransactionPool()
+	assert.True(s.T(), errors.Is(err, ErrResponseTooLarge), "Limit must apply to the decompressed body.")
+
+	dc = NewDaemonClient(s.ts.URL, "username", "password", WithHeaders(http.Header{"Accept-Encoding": {"deflate"}}))
+	block, err := dc.GetBlock(1, "")
+	if assert.NoError(s.T(), err) {
+		assert.Len(s.T(), block.Blob, 2048)
+	}
+}
+
+func (s *responseTestSuite) TestSizeReader() {
+	r := &sizeReader{r: strings.NewReader("0123456789"), method: "test", limit: 10}
+	data, err := ioutil.ReadAll(r)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "0123456789", string(data))
+
+	r = &sizeReader{r: strings.NewReader("0123456789"), method: "test", limit: 9}
+	_, err = ioutil.ReadAll(r)
+	assert.EqualError(s.T(), err, "Response is too large: test exceeds 9 bytes")
+
+	r = &sizeReader{r: strings.NewReader("0123456789")}
+	data, err = ioutil.ReadAll(r)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(len(data)), r.n)
+}
+
+func (s *responseTestSuite) TestEmptyResponse() {
+	assert.Equal(s.T(), io.ErrUnexpectedEOF, decodeResponse(strings.NewReader(""), &struct{}{}))
+}
+
+func (s *responseTestSuite) TestReplyDecoder() {
+	var info InfoResponse
+	res := &jsonRPCReply{Result: replyDecoder{reply: &info, payment: newPayment(nil)}}
+	err := decodeResponse(strings.NewReader(`{"id":1,"result":{"height":5,"credits":20,"top_hash":"top","status":"OK"}}`), res)
+	assert.NoError(s.T(), err)
+	assert.True(s.T(), res.Result.decoded)
+	assert.Equal(s.T(), uint(5), info.Height)
+	assert.Equal(s.T(), uint(20), res.Result.payment.credits)
+	assert.Equal(s.T(), "top", res.Result.payment.topHash)
+
+	res = &jsonRPCReply{Result: replyDecoder{reply: &info}}
+	assert.NoError(s.T(), decodeResponse(strings.NewReader(`{"id":1,"result":null}`), res))
+	assert.False(s.T(), res.Result.decoded)
+
+	res = &jsonRPCReply{Result: replyDecoder{reply: &info}}
+	assert.Error(s.T(), decodeResponse(strings.NewReader(`{"id":1,"result":{"height":"5"}}`), res))
+}
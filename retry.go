@@ -0,0 +1,210 @@
+package xmrrpc
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+)
+
+type Idempotency int
+
+const (
+	Idempotent Idempotency = iota
+	Conditional
+	NonIdempotent
+)
+
+var methodIdempotency = map[string]Idempotency{
+	"get_block_count":             Idempotent,
+	"on_get_block_hash":           Idempotent,
+	"get_block_template":          Idempotent,
+	"submit_block":                NonIdempotent,
+	"get_last_block_header":       Idempotent,
+	"get_block_header_by_hash":    Idempotent,
+	"get_block_header_by_height":  Idempotent,
+	"get_block_headers_range":     Idempotent,
+	"get_block":                   Idempotent,
+	"get_connections":             Idempotent,
+	"get_info":                    Idempotent,
+	"hard_fork_info":              Idempotent,
+	"set_bans":                    NonIdempotent,
+	"get_bans":                    Idempotent,
+	"flush_txpool":                Conditional,
+	"get_output_histogram":        Idempotent,
+	"get_version":                 Idempotent,
+	"get_coinbase_tx_sum":         Idempotent,
+	"get_fee_estimate":            Idempotent,
+	"get_alternate_chains":        Idempotent,
+	"relay_tx":                    Conditional,
+	"sync_info":                   Idempotent,
+	"get_txpool_backlog":          Idempotent,
+	"get_output_distribution":     Idempotent,
+	"get_miner_data":              Idempotent,
+	"calc_pow":                    Idempotent,
+	"add_aux_pow":                 Idempotent,
+	"flush_cache":                 Conditional,
+	"prune_blockchain":            Conditional,
+	"get_txids_loose":             Idempotent,
+	"generateblocks":              NonIdempotent,
+	"banned":                      Idempotent,
+	"rpc_access_info":             Idempotent,
+	"rpc_access_submit_nonce":     NonIdempotent,
+	"rpc_access_pay":              NonIdempotent,
+	"rpc_access_tracking":         Conditional,
+	"rpc_access_data":             Idempotent,
+	"rpc_access_account":          NonIdempotent,
+	"get_height":                  Idempotent,
+	"get_blocks.bin":              Idempotent,
+	"get_hashes.bin":              Idempotent,
+	"get_o_indexes.bin":           Idempotent,
+	"get_outs":                    Idempotent,
+	"get_outs.bin":                Idempotent,
+	"get_transactions":            Idempotent,
+	"get_alt_blocks_hashes":       Idempotent,
+	"is_key_image_spent":          Idempotent,
+	"send_raw_transaction":        NonIdempotent,
+	"start_mining":                Conditional,
+	"stop_mining":                 Conditional,
+	"mining_status":               Idempotent,
+	"save_bc":                     Conditional,
+	"get_peer_list":               Idempotent,
+	"set_log_hash_rate":           Conditional,
+	"set_log_level":               Conditional,
+	"set_log_categories":          Conditional,
+	"get_transaction_pool":        Idempotent,
+	"get_transaction_pool_stats":  Idempotent,
+	"stop_daemon":                 NonIdempotent,
+	"get_limit":                   Idempotent,
+	"set_limit":                   Conditional,
+	"out_peers":                   Conditional,
+	"in_peers":                    Conditional,
+	"start_save_graph":            Conditional,
+	"stop_save_graph":             Conditional,
+	"update":                      Conditional,
+	"set_bootstrap_daemon":        Conditional,
+	"get_public_nodes":            Idempotent,
+	"get_net_stats":               Idempotent,
+	"get_transaction_pool_hashes": Idempotent,
+	"pop_blocks":                  NonIdempotent,
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+	Retryable:      IsRetryable,
+}
+
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	Jitter         float64
+	Retryable      func(err error) bool
+}
+
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(dc *DaemonClient) {
+		dc.retry = &policy
+	}
+}
+
+// MethodIdempotency reports whether the daemon method is safe to retry.
+// Conditional methods are retried only when the request was certainly
+// not processed, unknown methods are treated as conditional.
+func MethodIdempotency(method string) Idempotency {
+	if idempotency, ok := methodIdempotency[method]; ok {
+		return idempotency
+	}
+
+	return Conditional
+}
+
+func requestNotProcessed(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusServiceUnavailable || httpErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	return errors.Is(err, ErrStatusBusy) || errors.Is(err, ErrCoreBusy) || errors.Is(err, ErrCircuitOpen)
+}
+
+func retryAllowed(method string, err error) bool {
+	switch MethodIdempotency(method) {
+	case Idempotent:
+		return true
+	case Conditional:
+		return requestNotProcessed(err)
+	}
+
+	return false
+}
+
+// retryAllowed reports whether every method performed by inv may be retried.
+func (inv *Invocation) retryAllowed(err error) bool {
+	for _, method := range inv.methods() {
+		if !retryAllowed(method, err) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	d := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(d)
+}
+
+func (p *RetryPolicy) shouldRetry(inv *Invocation, err error) bool {
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+
+	return retryable(err) && inv.retryAllowed(err)
+}
+
+func (p *RetryPolicy) do(ctx context.Context, inv *Invocation, f func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := f()
+		if err == nil || attempt >= p.MaxAttempts || ctx.Err() != nil || errors.Is(err, ErrCircuitOpen) || !p.shouldRetry(inv, err) {
+			return err
+		}
+
+		timer := time.NewTimer(p.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		resetResult(inv.Result)
+	}
+}
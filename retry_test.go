@@ -0,0 +1,218 @@
+package xmrrpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type retryTestSuite struct {
+	suite.Suite
+	ts       *httptest.Server
+	requests int32
+	failures int32
+	status   int
+}
+
+func TestRetryTestSuite(t *testing.T) {
+	suite.Run(t, new(retryTestSuite))
+}
+
+func (s *retryTestSuite) SetupTest() {
+	s.requests, s.failures, s.status = 0, 0, http.StatusServiceUnavailable
+	s.ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&s.requests, 1) <= atomic.LoadInt32(&s.failures) {
+			if s.status == http.StatusOK {
+				w.Write([]byte(`{"status":"BUSY"}`))
+				return
+			}
+
+			w.WriteHeader(s.status)
+			return
+		}
+
+		w.Write([]byte(`{"status":"OK","height":10}`))
+	}))
+}
+
+func (s *retryTestSuite) TearDownTest() {
+	s.ts.Close()
+}
+
+func (s *retryTestSuite) policy() RetryPolicy {
+	policy := DefaultRetryPolicy
+	policy.InitialBackoff = time.Millisecond
+	return policy
+}
+
+func (s *retryTestSuite) TestMethodIdempotency() {
+	for _, method := range []string{"submit_block", "send_raw_transaction", "stop_daemon", "set_bans"} {
+		assert.Equal(s.T(), NonIdempotent, MethodIdempotency(method), method)
+	}
+
+	assert.Equal(s.T(), Idempotent, MethodIdempotency("get_info"))
+	assert.Equal(s.T(), Idempotent, MethodIdempotency("get_height"))
+	assert.Equal(s.T(), Conditional, MethodIdempotency("start_mining"))
+	assert.Equal(s.T(), Conditional, MethodIdempotency("unknown_method"))
+}
+
+func (s *retryTestSuite) TestRetryAllowed() {
+	dialErr := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	readErr := &net.OpError{Op: "read", Err: errors.New("connection reset by peer")}
+
+	assert.True(s.T(), retryAllowed("get_info", readErr))
+	assert.True(s.T(), retryAllowed("start_mining", dialErr))
+	assert.True(s.T(), retryAllowed("start_mining", &StatusError{Status: "BUSY"}))
+	assert.False(s.T(), retryAllowed("start_mining", readErr))
+	assert.False(s.T(), retryAllowed("send_raw_transaction", dialErr))
+}
+
+func (s *retryTestSuite) TestIsRetryable() {
+	assert.True(s.T(), IsRetryable(&net.OpError{Op: "read", Err: syscall.ECONNRESET}))
+	assert.True(s.T(), IsRetryable(&url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}))
+	assert.True(s.T(), IsRetryable(&url.Error{Op: "Post", Err: io.EOF}))
+	assert.True(s.T(), IsRetryable(io.ErrUnexpectedEOF))
+	assert.True(s.T(), IsRetryable(context.DeadlineExceeded))
+	assert.True(s.T(), IsRetryable(&HTTPError{StatusCode: http.StatusBadGateway}))
+	assert.True(s.T(), IsRetryable(&StatusError{Status: "BUSY"}))
+	assert.True(s.T(), IsRetryable(&RPCError{Code: -9}))
+	assert.False(s.T(), IsRetryable(&RPCError{Code: -7}))
+	assert.False(s.T(), IsRetryable(&StatusError{Status: "Failed"}))
+	assert.False(s.T(), IsRetryable(&HTTPError{StatusCode: http.StatusUnauthorized}))
+	assert.False(s.T(), IsRetryable(context.Canceled))
+}
+
+func (s *retryTestSuite) TestIsRetryablePermanent() {
+	for _, err := range []error{
+		errors.New("Unexpected response id: 1, expected: 2"),
+		fmt.Errorf("%w: get_height exceeds 10 bytes", ErrResponseTooLarge),
+		&json.SyntaxError{},
+		ErrProxyRequired,
+		&url.Error{Op: "Post", Err: ErrCertificateNotAllowed},
+		&url.Error{Op: "Post", Err: &net.OpError{Op: "proxyconnect", Err: ErrCertificateNotAllowed}},
+		&url.Error{Op: "Post", Err: x509.UnknownAuthorityError{}},
+		&url.Error{Op: "Post", Err: &tls.CertificateVerificationError{Err: x509.HostnameError{}}},
+	} {
+		assert.False(s.T(), IsRetryable(err), err.Error())
+	}
+}
+
+func (s *retryTestSuite) TestRetryPermanent() {
+	s.failures, s.status = 5, http.StatusOK
+
+	// A BUSY body over the limit fails with ErrResponseTooLarge.
+	_, err := NewDaemonClient(s.ts.URL, "username", "password", WithRetryPolicy(s.policy()), WithMaxResponseSize(10)).GetHeight()
+	assert.ErrorIs(s.T(), err, ErrResponseTooLarge)
+	assert.Equal(s.T(), int32(1), s.requests)
+
+	malformed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.requests, 1)
+		w.Write([]byte(`{"status":`))
+		w.Write([]byte(`]`))
+	}))
+	defer malformed.Close()
+
+	s.requests = 0
+	_, err = NewDaemonClient(malformed.URL, "username", "password", WithRetryPolicy(s.policy())).GetHeight()
+	assert.Error(s.T(), err)
+	assert.Equal(s.T(), int32(1), s.requests)
+}
+
+func (s *retryTestSuite) TestBackoff() {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+	assert.Equal(s.T(), 100*time.Millisecond, policy.backoff(1))
+	assert.Equal(s.T(), 200*time.Millisecond, policy.backoff(2))
+	assert.Equal(s.T(), 400*time.Millisecond, policy.backoff(3))
+	assert.Equal(s.T(), time.Second, policy.backoff(10))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := policy.backoff(2)
+		assert.True(s.T(), d >= 100*time.Millisecond && d <= 300*time.Millisecond, d)
+	}
+}
+
+func (s *retryTestSuite) TestRetry() {
+	s.failures = 2
+
+	res, err := NewDaemonClient(s.ts.URL, "username", "password", WithRetryPolicy(s.policy())).GetHeight()
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), uint(10), res.Height)
+		assert.Equal(s.T(), int32(3), s.requests)
+	}
+}
+
+func (s *retryTestSuite) TestRetryExhausted() {
+	s.failures = 5
+
+	_, err := NewDaemonClient(s.ts.URL, "username", "password", WithRetryPolicy(s.policy())).GetHeight()
+	if assert.Error(s.T(), err) {
+		assert.Equal(s.T(), int32(3), s.requests)
+	}
+}
+
+func (s *retryTestSuite) TestRetryUnsafe() {
+	s.failures = 1
+
+	_, err := NewDaemonClient(s.ts.URL, "username", "password", WithRetryPolicy(s.policy())).SendRawTransaction("", false)
+	if assert.Error(s.T(), err) {
+		assert.Equal(s.T(), int32(1), s.requests)
+	}
+}
+
+func (s *retryTestSuite) TestRetryConditional() {
+	s.failures, s.status = 1, http.StatusInternalServerError
+
+	_, err := NewDaemonClient(s.ts.URL, "username", "password", WithRetryPolicy(s.policy())).StartMining(false, false, "", 1)
+	if assert.Error(s.T(), err) {
+		assert.Equal(s.T(), int32(1), s.requests)
+	}
+
+	s.requests, s.failures, s.status = 0, 1, http.StatusOK
+
+	res, err := NewDaemonClient(s.ts.URL, "username", "password", WithRetryPolicy(s.policy())).StartMining(false, false, "", 1)
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), "OK", res.Status)
+		assert.Equal(s.T(), int32(2), s.requests)
+	}
+}
+
+func (s *retryTestSuite) TestRetryPredicate() {
+	s.failures = 1
+
+	policy := s.policy()
+	policy.Retryable = func(err error) bool { return false }
+
+	_, err := NewDaemonClient(s.ts.URL, "username", "password", WithRetryPolicy(policy)).GetHeight()
+	if assert.Error(s.T(), err) {
+		assert.Equal(s.T(), int32(1), s.requests)
+	}
+}
+
+func (s *retryTestSuite) TestRetryContext() {
+	s.failures = 5
+
+	policy := s.policy()
+	policy.InitialBackoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := NewDaemonClient(s.ts.URL, "username", "password", WithRetryPolicy(policy)).GetHeightContext(ctx)
+	assert.Equal(s.T(), context.DeadlineExceeded, err)
+}
@@ -0,0 +1,69 @@
+package xmrrpc
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+var ErrCertificateNotAllowed = errors.New("Certificate fingerprint is not allowed")
+
+func (dc *DaemonClient) tls() *tls.Config {
+	if dc.tlsConfig == nil {
+		dc.tlsConfig = &tls.Config{}
+	}
+
+	return dc.tlsConfig
+}
+
+func WithRootCAs(roots *x509.CertPool) Option {
+	return func(dc *DaemonClient) {
+		dc.tls().RootCAs = roots
+	}
+}
+
+func WithClientCertificate(cert tls.Certificate) Option {
+	return func(dc *DaemonClient) {
+		dc.tls().Certificates = append(dc.tls().Certificates, cert)
+	}
+}
+
+func WithMinTLSVersion(version uint16) Option {
+	return func(dc *DaemonClient) {
+		dc.tls().MinVersion = version
+	}
+}
+
+// WithCertificateFingerprints accepts only daemon certificates with one of the
+// given SHA-256 fingerprints (hex, colons allowed, as in --rpc-ssl-allowed-fingerprints)
+// instead of verifying the chain, so self-signed certificates can be used.
+func WithCertificateFingerprints(fingerprints ...string) Option {
+	return func(dc *DaemonClient) {
+		allowed := map[string]bool{}
+		for _, fp := range fingerprints {
+			allowed[normalizeFingerprint(fp)] = true
+		}
+
+		config := dc.tls()
+		config.InsecureSkipVerify = true
+		config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return ErrCertificateNotAllowed
+			}
+
+			sum := sha256.Sum256(rawCerts[0])
+			if !allowed[hex.EncodeToString(sum[:])] {
+				return ErrCertificateNotAllowed
+			}
+
+			return nil
+		}
+	}
+}
+
+func normalizeFingerprint(fp string) string {
+	return strings.ToLower(strings.NewReplacer(":", "", " ", "").Replace(fp))
+}
@@ -0,0 +1,155 @@
+package xmrrpc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type tlsTestSuite struct {
+	suite.Suite
+	ts *httptest.Server
+}
+
+func TestTLSTestSuite(t *testing.T) {
+	suite.Run(t, new(tlsTestSuite))
+}
+
+func (s *tlsTestSuite) SetupTest() {
+	s.ts = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+			w.Header().Set("X-Client-Certificate", r.TLS.PeerCertificates[0].Subject.CommonName)
+		}
+		w.Write([]byte(`{"status":"OK","height":100}`))
+	}))
+	s.ts.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+}
+
+func (s *tlsTestSuite) TearDownTest() {
+	s.ts.Close()
+}
+
+func (s *tlsTestSuite) certificate(name string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	s.Require().NoError(err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func (s *tlsTestSuite) fingerprint() string {
+	sum := sha256.Sum256(s.ts.Certificate().Raw)
+
+	var parts []string
+	for _, b := range sum {
+		parts = append(parts, strings.ToUpper(hex.EncodeToString([]byte{b})))
+	}
+
+	return strings.Join(parts, ":")
+}
+
+func (s *tlsTestSuite) TestUnknownAuthority() {
+	s.ts.StartTLS()
+
+	_, err := NewDaemonClient(s.ts.URL, "username", "password").GetHeight()
+	assert.Error(s.T(), err)
+}
+
+func (s *tlsTestSuite) TestRootCAs() {
+	s.ts.StartTLS()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(s.ts.Certificate())
+
+	res, err := NewDaemonClient(s.ts.URL, "username", "password", WithRootCAs(roots)).GetHeight()
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), uint(100), res.Height)
+	}
+}
+
+func (s *tlsTestSuite) TestFingerprints() {
+	s.ts.StartTLS()
+
+	res, err := NewDaemonClient(s.ts.URL, "username", "password", WithCertificateFingerprints("00:11", s.fingerprint())).GetHeight()
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), uint(100), res.Height)
+	}
+
+	_, err = NewDaemonClient(s.ts.URL, "username", "password", WithCertificateFingerprints(strings.Repeat("00", 32))).GetHeight()
+	assert.True(s.T(), errors.Is(err, ErrCertificateNotAllowed))
+}
+
+func (s *tlsTestSuite) TestClientCertificate() {
+	s.ts.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	s.ts.StartTLS()
+
+	_, err := NewDaemonClient(s.ts.URL, "username", "password", WithCertificateFingerprints(s.fingerprint())).GetHeight()
+	assert.Error(s.T(), err)
+
+	client := &http.Client{Transport: &http.Transport{}}
+	dc := NewDaemonClient(s.ts.URL, "username", "password",
+		WithHTTPClient(client),
+		WithClientCertificate(s.certificate("wallet")),
+		WithCertificateFingerprints(s.fingerprint()),
+	)
+	res, err := dc.GetHeight()
+	if assert.NoError(s.T(), err) {
+		assert.Equal(s.T(), "OK", res.Status)
+	}
+	if config := client.Transport.(*http.Transport).TLSClientConfig; config != nil {
+		assert.Empty(s.T(), config.Certificates, "Shared transport must not be modified.")
+	}
+}
+
+func (s *tlsTestSuite) TestMinTLSVersion() {
+	s.ts.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	s.ts.StartTLS()
+
+	_, err := NewDaemonClient(s.ts.URL, "username", "password", WithCertificateFingerprints(s.fingerprint()), WithMinTLSVersion(tls.VersionTLS13)).GetHeight()
+	assert.Error(s.T(), err)
+
+	_, err = NewDaemonClient(s.ts.URL, "username", "password", WithCertificateFingerprints(s.fingerprint()), WithMinTLSVersion(tls.VersionTLS12)).GetHeight()
+	assert.NoError(s.T(), err)
+}
+
+func (s *tlsTestSuite) TestTransport() {
+	client := &http.Client{Transport: http.NewFileTransport(http.Dir("."))}
+	dc := NewDaemonClient(s.ts.URL, "username", "password", WithHTTPClient(client), WithMinTLSVersion(tls.VersionTLS12))
+	assert.Equal(s.T(), client.Transport, dc.client.Transport)
+	_, err := dc.GetHeight()
+	assert.ErrorIs(s.T(), err, ErrUnsupportedTransport, "TLS options must not be dropped silently.")
+
+	dc = NewDaemonClient(s.ts.URL, "username", "password", WithMinTLSVersion(tls.VersionTLS12))
+	if assert.IsType(s.T(), &http.Transport{}, dc.client.Transport) {
+		assert.Equal(s.T(), uint16(tls.VersionTLS12), dc.client.Transport.(*http.Transport).TLSClientConfig.MinVersion)
+	}
+	if config := http.DefaultTransport.(*http.Transport).TLSClientConfig; config != nil {
+		assert.Zero(s.T(), config.MinVersion, "Default transport must not be modified.")
+	}
+}
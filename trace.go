@@ -0,0 +1,119 @@
+package xmrrpc
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const tracerName = "github.com/stdfox/xmrrpc"
+
+// Params recorded as span attributes, keyed by their JSON name.
+var tracedParams = map[string]string{
+	"height":       "monero.height",
+	"start_height": "monero.start_height",
+	"end_height":   "monero.end_height",
+	"hash":         "monero.hash",
+	"hashes":       "monero.hashes",
+	"txs_hashes":   "monero.txs_hashes",
+}
+
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(dc *DaemonClient) {
+		if provider == nil {
+			provider = noop.NewTracerProvider()
+		}
+
+		dc.tracer = provider.Tracer(tracerName)
+	}
+}
+
+func (dc *DaemonClient) startSpan(ctx context.Context, inv *Invocation) (context.Context, func(err error)) {
+	ctx, span := dc.tracer.Start(ctx, inv.Method, trace.WithSpanKind(trace.SpanKindClient))
+	if !span.IsRecording() {
+		return ctx, func(err error) { span.End() }
+	}
+
+	system := "monero"
+	if inv.IsJSONRPC() {
+		system = "jsonrpc"
+	}
+
+	span.SetAttributes(
+		attribute.String("rpc.system", system),
+		attribute.String("rpc.method", inv.Method),
+		attribute.String("monero.endpoint", inv.Endpoint),
+		attribute.String("monero.node", redactURL(dc.endpoint)),
+	)
+	span.SetAttributes(paramAttributes(inv.Params)...)
+
+	return ctx, func(err error) {
+		if status, _ := replyStatus(inv.Result); status != "" {
+			span.SetAttributes(attribute.String("monero.status", status))
+		}
+		endSpan(span, err)
+	}
+}
+
+func (dc *DaemonClient) challenged(ctx context.Context) (context.Context, func(err error)) {
+	if dc.metrics != nil {
+		dc.metrics.Reauth()
+	}
+
+	if dc.tracer == nil {
+		return ctx, func(err error) {}
+	}
+
+	ctx, span := dc.tracer.Start(ctx, "digest challenge", trace.WithSpanKind(trace.SpanKindClient))
+	return ctx, func(err error) {
+		endSpan(span, err)
+	}
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func paramAttributes(params interface{}) []attribute.KeyValue {
+	raw, err := json.Marshal(params)
+	if err != nil || !strings.HasPrefix(string(raw), "{") {
+		return nil
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil
+	}
+
+	var attrs []attribute.KeyValue
+	for name, key := range tracedParams {
+		switch v := values[name].(type) {
+		case float64:
+			attrs = append(attrs, attribute.Int64(key, int64(v)))
+		case string:
+			if v == "" {
+				continue
+			}
+			attrs = append(attrs, attribute.String(key, v))
+		case []interface{}:
+			var items []string
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					items = append(items, s)
+				}
+			}
+			attrs = append(attrs, attribute.StringSlice(key, items))
+		}
+	}
+
+	return attrs
+}
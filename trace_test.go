@@ -0,0 +1,143 @@
+package xmrrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type traceTestSuite struct {
+	suite.Suite
+	ts       *httptest.Server
+	recorder *tracetest.SpanRecorder
+	provider *sdktrace.TracerProvider
+}
+
+func TestTraceTestSuite(t *testing.T) {
+	suite.Run(t, new(traceTestSuite))
+}
+
+func (s *traceTestSuite) SetupTest() {
+	s.recorder = tracetest.NewSpanRecorder()
+	s.provider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(s.recorder))
+	s.ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.Header().Add("WWW-authenticate", `Digest qop="auth",algorithm=MD5,realm="monero-rpc",nonce="nonce",stale=false`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/json_rpc":
+			var req jsonRPCRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.Method == "get_block" {
+				w.Write([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"status":"OK","blob":"00"}}`, req.ID)))
+				return
+			}
+			w.Write([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"error":{"code":-2,"message":"Too big height"}}`, req.ID)))
+		case "/send_raw_transaction":
+			w.Write([]byte(`{"status":"Failed","reason":"double spend"}`))
+		}
+	}))
+}
+
+func (s *traceTestSuite) TearDownTest() {
+	s.ts.Close()
+}
+
+func (s *traceTestSuite) attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+
+	return attrs
+}
+
+func (s *traceTestSuite) TestSpans() {
+	dc := NewDaemonClient(s.ts.URL, "username", "password", WithTracerProvider(s.provider))
+
+	_, err := dc.GetBlock(42, "")
+	assert.NoError(s.T(), err)
+
+	spans := s.recorder.Ended()
+	if !assert.Len(s.T(), spans, 2) {
+		return
+	}
+
+	challenge, call := spans[0], spans[1]
+	assert.Equal(s.T(), "get_block", call.Name())
+	assert.Equal(s.T(), trace.SpanKindClient, call.SpanKind())
+	assert.Equal(s.T(), codes.Unset, call.Status().Code)
+
+	attrs := s.attributes(call)
+	assert.Equal(s.T(), "jsonrpc", attrs["rpc.system"].AsString())
+	assert.Equal(s.T(), "get_block", attrs["rpc.method"].AsString())
+	assert.Equal(s.T(), "/json_rpc", attrs["monero.endpoint"].AsString())
+	assert.Equal(s.T(), s.ts.URL, attrs["monero.node"].AsString())
+	assert.Equal(s.T(), int64(42), attrs["monero.height"].AsInt64())
+	assert.Equal(s.T(), "OK", attrs["monero.status"].AsString())
+	assert.Equal(s.T(), int64(http.StatusOK), attrs["http.response.status_code"].AsInt64())
+
+	assert.Equal(s.T(), "digest challenge", challenge.Name())
+	assert.Equal(s.T(), call.SpanContext().SpanID(), challenge.Parent().SpanID())
+
+	_, err = dc.GetBlock(43, "")
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), s.recorder.Ended(), 3, "Session must be reused without a challenge.")
+}
+
+func (s *traceTestSuite) TestErrors() {
+	dc := NewDaemonClient(s.ts.URL, "username", "password", WithTracerProvider(s.provider))
+
+	_, err := dc.GetBlockHeaderByHeight(1 << 30)
+	assert.Error(s.T(), err)
+	_, err = dc.SendRawTransaction("deadbeef", false)
+	assert.Error(s.T(), err)
+
+	spans := s.recorder.Ended()
+	if !assert.Len(s.T(), spans, 3) {
+		return
+	}
+
+	assert.Equal(s.T(), "get_block_header_by_height", spans[1].Name())
+	assert.Equal(s.T(), codes.Error, spans[1].Status().Code)
+	assert.Equal(s.T(), "Too big height", spans[1].Status().Description)
+
+	assert.Equal(s.T(), "send_raw_transaction", spans[2].Name())
+	assert.Equal(s.T(), codes.Error, spans[2].Status().Code)
+	attrs := s.attributes(spans[2])
+	assert.Equal(s.T(), "monero", attrs["rpc.system"].AsString())
+	assert.Equal(s.T(), "Failed", attrs["monero.status"].AsString())
+	assert.NotContains(s.T(), attrs, attribute.Key("monero.height"))
+}
+
+func (s *traceTestSuite) TestParamAttributes() {
+	attrs := paramAttributes(map[string]interface{}{"txs_hashes": []string{"a", "b"}, "hash": "c", "miner_address": "d"})
+	assert.ElementsMatch(s.T(), []attribute.KeyValue{
+		attribute.StringSlice("monero.txs_hashes", []string{"a", "b"}),
+		attribute.String("monero.hash", "c"),
+	}, attrs)
+
+	assert.Empty(s.T(), paramAttributes([]int{1}))
+	assert.Empty(s.T(), paramAttributes(nil))
+}
+
+func (s *traceTestSuite) TestNoop() {
+	dc := NewDaemonClient(s.ts.URL, "username", "password")
+
+	_, err := dc.GetBlock(42, "")
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), s.recorder.Ended())
+}